@@ -0,0 +1,195 @@
+package youtubechat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+// UnhandledStat counts the actions of one kind LiveChat could not decode
+type UnhandledStat struct {
+	Key         string // action key, e.g. "addChatItemAction"
+	RendererKey string // renderer key of the item the action carries, if any
+	Count       int
+	Sample      json.RawMessage // the first action seen
+	FirstSeen   time.Time
+	LastSeen    time.Time
+}
+
+// Diagnostics reports what LiveChat could not decode since it was created,
+// leaving out what registered renderer parsers handled
+type Diagnostics struct {
+	Unhandled []UnhandledStat // most frequent first
+}
+
+type diagnostics struct {
+	mu        sync.Mutex
+	unhandled map[string]*UnhandledStat
+	dir       string // where to write fixtures of new kinds, if set
+}
+
+func diagnosticsKey(action types.UnhandledAction) string {
+	return action.Key + "/" + action.RendererKey
+}
+
+// record counts the actions and returns those of kinds not seen before
+func (d *diagnostics) record(actions []types.UnhandledAction, now time.Time) []types.UnhandledAction {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.unhandled == nil {
+		d.unhandled = map[string]*UnhandledStat{}
+	}
+
+	var first []types.UnhandledAction
+	for _, action := range actions {
+		key := diagnosticsKey(action)
+		stat, ok := d.unhandled[key]
+		if !ok {
+			stat = &UnhandledStat{
+				Key:         action.Key,
+				RendererKey: action.RendererKey,
+				Sample:      action.Raw,
+				FirstSeen:   now,
+			}
+			d.unhandled[key] = stat
+			first = append(first, action)
+		}
+		stat.Count++
+		stat.LastSeen = now
+	}
+	return first
+}
+
+func (d *diagnostics) snapshot() Diagnostics {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var result Diagnostics
+	for _, stat := range d.unhandled {
+		result.Unhandled = append(result.Unhandled, *stat)
+	}
+	sort.Slice(result.Unhandled, func(i, j int) bool {
+		a, b := result.Unhandled[i], result.Unhandled[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Key+a.RendererKey < b.Key+b.RendererKey
+	})
+	return result
+}
+
+// fixtureName follows testdata naming: get_live_chat.unhandled-live-chat-foo-renderer.json
+func fixtureName(action types.UnhandledAction) string {
+	key := action.RendererKey
+	if key == "" {
+		key = action.Key
+	}
+	if key == "" {
+		key = "action"
+	}
+
+	var b strings.Builder
+	for i, r := range key {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return fmt.Sprintf("get_live_chat.unhandled-%s.json", b.String())
+}
+
+// writeFixture saves the action as a get_live_chat response that the parser
+// tests can load. An existing file is kept.
+func writeFixture(dir string, action types.UnhandledAction) error {
+	type continuation struct {
+		InvalidationContinuationData struct {
+			TimeoutMs    int    `json:"timeoutMs"`
+			Continuation string `json:"continuation"`
+		} `json:"invalidationContinuationData"`
+	}
+	var response struct {
+		ResponseContext      struct{} `json:"responseContext"`
+		ContinuationContents struct {
+			LiveChatContinuation struct {
+				Continuations []continuation    `json:"continuations"`
+				Actions       []json.RawMessage `json:"actions"`
+			} `json:"liveChatContinuation"`
+		} `json:"continuationContents"`
+	}
+
+	var cont continuation
+	cont.InvalidationContinuationData.TimeoutMs = 10000
+	cont.InvalidationContinuationData.Continuation = "test-continuation:01"
+	chat := &response.ContinuationContents.LiveChatContinuation
+	chat.Continuations = []continuation{cont}
+	chat.Actions = []json.RawMessage{action.Raw}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(response); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, fixtureName(action))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WithDiagnosticsDir writes the first action of every unhandled kind to dir as
+// a get_live_chat.unhandled-*.json fixture, ready to be moved to testdata
+func WithDiagnosticsDir(dir string) Option {
+	return func(lc *LiveChat) {
+		lc.diagnostics.dir = dir
+	}
+}
+
+// Diagnostics returns the unhandled action and renderer keys seen so far
+func (lc *LiveChat) Diagnostics() Diagnostics {
+	return lc.diagnostics.snapshot()
+}
+
+func (lc *LiveChat) recordUnhandled(unhandled []types.UnhandledAction) {
+	if len(unhandled) == 0 {
+		return
+	}
+	first := lc.diagnostics.record(unhandled, time.Now())
+	if lc.diagnostics.dir == "" {
+		return
+	}
+	for _, action := range first {
+		if err := writeFixture(lc.diagnostics.dir, action); err != nil {
+			lc.emitError(fmt.Errorf("diagnostics: %w", err))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package youtubechat
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+func TestDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50, WithDiagnosticsDir(dir))
+
+	actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.unknown.json"))
+	lc.emitActions(actions)
+	actions, _ = ParseChatActions(loadChatResponse(t, "get_live_chat.unknown.json"))
+	actions.Unhandled = actions.Unhandled[:1]
+	lc.emitActions(actions)
+
+	diag := lc.Diagnostics()
+	if len(diag.Unhandled) != 3 {
+		t.Fatalf("Expected 3 unhandled kinds, got %d", len(diag.Unhandled))
+	}
+	renderer := diag.Unhandled[0]
+	if renderer.Key != "addChatItemAction" || renderer.RendererKey != "liveChatFooRenderer" || renderer.Count != 2 {
+		t.Errorf("Unexpected stat %+v", renderer)
+	}
+	if renderer.Sample == nil || renderer.FirstSeen.IsZero() || renderer.LastSeen.Before(renderer.FirstSeen) {
+		t.Errorf("Expected sample and times, got %+v", renderer)
+	}
+	if banner := diag.Unhandled[1]; banner.Key != "addBannerToLiveChatCommand" || banner.RendererKey != "liveChatBannerFooRenderer" {
+		t.Errorf("Unexpected stat %+v", banner)
+	}
+	if action := diag.Unhandled[2]; action.Key != "fooAction" || action.RendererKey != "" || action.Count != 1 {
+		t.Errorf("Unexpected stat %+v", action)
+	}
+
+	// Fixtures parse back to the same unhandled action
+	for name, key := range map[string]string{
+		"get_live_chat.unhandled-live-chat-foo-renderer.json":        "liveChatFooRenderer",
+		"get_live_chat.unhandled-live-chat-banner-foo-renderer.json": "liveChatBannerFooRenderer",
+		"get_live_chat.unhandled-foo-action.json":                    "",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected fixture %s: %v", name, err)
+		}
+		var response types.GetLiveChatResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			t.Fatalf("Invalid fixture %s: %v", name, err)
+		}
+		parsed, continuation := ParseChatActions(response)
+		if continuation != "test-continuation:01" {
+			t.Errorf("Expected continuation in %s, got %q", name, continuation)
+		}
+		if len(parsed.Unhandled) != 1 || parsed.Unhandled[0].RendererKey != key {
+			t.Errorf("Unexpected actions from %s: %+v", name, parsed.Unhandled)
+		}
+	}
+}
+
+func TestDiagnostics_SkipsCustom(t *testing.T) {
+	parseFoo := func(raw json.RawMessage) (any, error) { return nil, nil }
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50,
+		WithRendererParser("liveChatFooRenderer", parseFoo), WithRendererParser("liveChatBannerFooRenderer", parseFoo))
+
+	actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.unknown.json"))
+	lc.emitActions(actions)
+
+	diag := lc.Diagnostics()
+	if len(diag.Unhandled) != 1 || diag.Unhandled[0].Key != "fooAction" {
+		t.Errorf("Expected only fooAction, got %+v", diag.Unhandled)
+	}
+}
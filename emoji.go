@@ -0,0 +1,424 @@
+package youtubechat
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+var (
+	// "1f44f", "u1f44f", "1f44f-1f3ff" or "1f44f_1f3ff"
+	regexEmojiCodepoints = regexp.MustCompile(`^u?([0-9a-fA-F]{4,6}(?:[-_][0-9a-fA-F]{4,6})*)$`)
+	// ".../emoji_u1f44f_1f3ff.svg" or ".../notoemoji/15.0/1f44f_1f3ff/72.png"
+	regexEmojiImageURL = regexp.MustCompile(`(?:emoji_u|notoemoji/[^/]+/)([0-9a-fA-F]{4,6}(?:_[0-9a-fA-F]{4,6})*)`)
+)
+
+// Skin-tone suffixes of CLDR names, longest first so "medium_light" wins over "light"
+var skinToneSuffixes = []struct {
+	suffix   string
+	modifier string
+}{
+	{"_medium_light_skin_tone", "\U0001F3FC"},
+	{"_medium_dark_skin_tone", "\U0001F3FE"},
+	{"_medium_skin_tone", "\U0001F3FD"},
+	{"_light_skin_tone", "\U0001F3FB"},
+	{"_dark_skin_tone", "\U0001F3FF"},
+	// Slack style ":clap::skin-tone-3:"
+	{"::skin-tone-2", "\U0001F3FB"},
+	{"::skin-tone-3", "\U0001F3FC"},
+	{"::skin-tone-4", "\U0001F3FD"},
+	{"::skin-tone-5", "\U0001F3FE"},
+	{"::skin-tone-6", "\U0001F3FF"},
+}
+
+// standardEmoji maps the shortcut names YouTube lists for its standard emoji,
+// CLDR names and their common aliases, to Unicode
+var standardEmoji = map[string]string{
+	"grinning_face":                   "😀",
+	"grinning":                        "😀",
+	"grinning_face_with_big_eyes":     "😃",
+	"smiley":                          "😃",
+	"grinning_face_with_smiling_eyes": "😄",
+	"smile":                           "😄",
+	"beaming_face_with_smiling_eyes":  "😁",
+	"grin":                            "😁",
+	"grinning_squinting_face":         "😆",
+	"laughing":                        "😆",
+	"grinning_face_with_sweat":        "😅",
+	"sweat_smile":                     "😅",
+	"rolling_on_the_floor_laughing":   "🤣",
+	"rofl":                            "🤣",
+	"face_with_tears_of_joy":          "😂",
+	"joy":                             "😂",
+	"slightly_smiling_face":           "🙂",
+	"upside_down_face":                "🙃",
+	"winking_face":                    "😉",
+	"wink":                            "😉",
+	"smiling_face_with_smiling_eyes":  "😊",
+	"blush":                           "😊",
+	"smiling_face_with_halo":          "😇",
+	"innocent":                        "😇",
+	"smiling_face_with_hearts":        "🥰",
+	"smiling_face_with_heart_eyes":    "😍",
+	"heart_eyes":                      "😍",
+	"star_struck":                     "🤩",
+	"face_blowing_a_kiss":             "😘",
+	"kissing_heart":                   "😘",
+	"face_savoring_food":              "😋",
+	"yum":                             "😋",
+	"face_with_tongue":                "😛",
+	"stuck_out_tongue":                "😛",
+	"winking_face_with_tongue":        "😜",
+	"stuck_out_tongue_winking_eye":    "😜",
+	"zany_face":                       "🤪",
+	"money_mouth_face":                "🤑",
+	"smiling_face_with_open_hands":    "🤗",
+	"hugging_face":                    "🤗",
+	"hugs":                            "🤗",
+	"face_with_hand_over_mouth":       "🤭",
+	"shushing_face":                   "🤫",
+	"thinking_face":                   "🤔",
+	"thinking":                        "🤔",
+	"zipper_mouth_face":               "🤐",
+	"face_with_raised_eyebrow":        "🤨",
+	"neutral_face":                    "😐",
+	"expressionless_face":             "😑",
+	"expressionless":                  "😑",
+	"face_without_mouth":              "😶",
+	"no_mouth":                        "😶",
+	"smirking_face":                   "😏",
+	"smirk":                           "😏",
+	"unamused_face":                   "😒",
+	"unamused":                        "😒",
+	"face_with_rolling_eyes":          "🙄",
+	"roll_eyes":                       "🙄",
+	"grimacing_face":                  "😬",
+	"grimacing":                       "😬",
+	"relieved_face":                   "😌",
+	"relieved":                        "😌",
+	"pensive_face":                    "😔",
+	"pensive":                         "😔",
+	"sleepy_face":                     "😪",
+	"sleepy":                          "😪",
+	"sleeping_face":                   "😴",
+	"sleeping":                        "😴",
+	"face_with_medical_mask":          "😷",
+	"mask":                            "😷",
+	"face_with_thermometer":           "🤒",
+	"nauseated_face":                  "🤢",
+	"face_vomiting":                   "🤮",
+	"hot_face":                        "🥵",
+	"cold_face":                       "🥶",
+	"face_with_crossed_out_eyes":      "😵",
+	"dizzy_face":                      "😵",
+	"exploding_head":                  "🤯",
+	"cowboy_hat_face":                 "🤠",
+	"partying_face":                   "🥳",
+	"smiling_face_with_sunglasses":    "😎",
+	"sunglasses":                      "😎",
+	"nerd_face":                       "🤓",
+	"confused_face":                   "😕",
+	"confused":                        "😕",
+	"worried_face":                    "😟",
+	"worried":                         "😟",
+	"slightly_frowning_face":          "🙁",
+	"face_with_open_mouth":            "😮",
+	"open_mouth":                      "😮",
+	"astonished_face":                 "😲",
+	"astonished":                      "😲",
+	"flushed_face":                    "😳",
+	"flushed":                         "😳",
+	"pleading_face":                   "🥺",
+	"fearful_face":                    "😨",
+	"fearful":                         "😨",
+	"anxious_face_with_sweat":         "😰",
+	"cold_sweat":                      "😰",
+	"crying_face":                     "😢",
+	"cry":                             "😢",
+	"loudly_crying_face":              "😭",
+	"sob":                             "😭",
+	"face_screaming_in_fear":          "😱",
+	"scream":                          "😱",
+	"confounded_face":                 "😖",
+	"confounded":                      "😖",
+	"persevering_face":                "😣",
+	"persevere":                       "😣",
+	"disappointed_face":               "😞",
+	"disappointed":                    "😞",
+	"downcast_face_with_sweat":        "😓",
+	"sweat":                           "😓",
+	"weary_face":                      "😩",
+	"weary":                           "😩",
+	"tired_face":                      "😫",
+	"yawning_face":                    "🥱",
+	"face_with_steam_from_nose":       "😤",
+	"triumph":                         "😤",
+	"enraged_face":                    "😡",
+	"rage":                            "😡",
+	"pout":                            "😡",
+	"angry_face":                      "😠",
+	"angry":                           "😠",
+	"face_with_symbols_on_mouth":      "🤬",
+	"cursing_face":                    "🤬",
+	"smiling_face_with_horns":         "😈",
+	"smiling_imp":                     "😈",
+	"skull":                           "💀",
+	"pile_of_poo":                     "💩",
+	"poop":                            "💩",
+	"clown_face":                      "🤡",
+	"ghost":                           "👻",
+	"alien":                           "👽",
+	"robot":                           "🤖",
+	"grinning_cat":                    "😺",
+	"smiley_cat":                      "😺",
+	"see_no_evil_monkey":              "🙈",
+	"see_no_evil":                     "🙈",
+	"hundred_points":                  "💯",
+	"100":                             "💯",
+	"anger_symbol":                    "💢",
+	"anger":                           "💢",
+	"collision":                       "💥",
+	"boom":                            "💥",
+	"dizzy":                           "💫",
+	"sweat_droplets":                  "💦",
+	"sweat_drops":                     "💦",
+	"zzz":                             "💤",
+	"red_heart":                       "❤️",
+	"heart":                           "❤️",
+	"orange_heart":                    "🧡",
+	"yellow_heart":                    "💛",
+	"green_heart":                     "💚",
+	"blue_heart":                      "💙",
+	"purple_heart":                    "💜",
+	"black_heart":                     "🖤",
+	"white_heart":                     "🤍",
+	"broken_heart":                    "💔",
+	"two_hearts":                      "💕",
+	"sparkling_heart":                 "💖",
+	"growing_heart":                   "💗",
+	"heartpulse":                      "💗",
+	"heart_with_arrow":                "💘",
+	"cupid":                           "💘",
+	"heart_with_ribbon":               "💝",
+	"gift_heart":                      "💝",
+	"kiss_mark":                       "💋",
+	"kiss":                            "💋",
+	"waving_hand":                     "👋",
+	"wave":                            "👋",
+	"raised_back_of_hand":             "🤚",
+	"raised_hand":                     "✋",
+	"hand":                            "✋",
+	"vulcan_salute":                   "🖖",
+	"ok_hand":                         "👌",
+	"pinched_fingers":                 "🤌",
+	"victory_hand":                    "✌️",
+	"v":                               "✌️",
+	"crossed_fingers":                 "🤞",
+	"love_you_gesture":                "🤟",
+	"sign_of_the_horns":               "🤘",
+	"metal":                           "🤘",
+	"call_me_hand":                    "🤙",
+	"backhand_index_pointing_left":    "👈",
+	"point_left":                      "👈",
+	"backhand_index_pointing_right":   "👉",
+	"point_right":                     "👉",
+	"backhand_index_pointing_up":      "👆",
+	"point_up_2":                      "👆",
+	"backhand_index_pointing_down":    "👇",
+	"point_down":                      "👇",
+	"index_pointing_up":               "☝️",
+	"point_up":                        "☝️",
+	"thumbs_up":                       "👍",
+	"+1":                              "👍",
+	"thumbsup":                        "👍",
+	"thumbs_down":                     "👎",
+	"-1":                              "👎",
+	"thumbsdown":                      "👎",
+	"raised_fist":                     "✊",
+	"fist":                            "✊",
+	"oncoming_fist":                   "👊",
+	"punch":                           "👊",
+	"clapping_hands":                  "👏",
+	"clap":                            "👏",
+	"raising_hands":                   "🙌",
+	"open_hands":                      "👐",
+	"palms_up_together":               "🤲",
+	"handshake":                       "🤝",
+	"folded_hands":                    "🙏",
+	"pray":                            "🙏",
+	"writing_hand":                    "✍️",
+	"flexed_biceps":                   "💪",
+	"muscle":                          "💪",
+	"eyes":                            "👀",
+	"brain":                           "🧠",
+	"fire":                            "🔥",
+	"sparkles":                        "✨",
+	"star":                            "⭐",
+	"glowing_star":                    "🌟",
+	"star2":                           "🌟",
+	"high_voltage":                    "⚡",
+	"zap":                             "⚡",
+	"party_popper":                    "🎉",
+	"tada":                            "🎉",
+	"confetti_ball":                   "🎊",
+	"wrapped_gift":                    "🎁",
+	"gift":                            "🎁",
+	"birthday_cake":                   "🎂",
+	"birthday":                        "🎂",
+	"trophy":                          "🏆",
+	"1st_place_medal":                 "🥇",
+	"video_game":                      "🎮",
+	"musical_note":                    "🎵",
+	"musical_notes":                   "🎶",
+	"notes":                           "🎶",
+	"microphone":                      "🎤",
+	"headphone":                       "🎧",
+	"headphones":                      "🎧",
+	"loudspeaker":                     "📢",
+	"bell":                            "🔔",
+	"money_bag":                       "💰",
+	"moneybag":                        "💰",
+	"money_with_wings":                "💸",
+	"gem_stone":                       "💎",
+	"gem":                             "💎",
+	"crown":                           "👑",
+	"rocket":                          "🚀",
+	"rainbow":                         "🌈",
+	"sun":                             "☀️",
+	"sunny":                           "☀️",
+	"crescent_moon":                   "🌙",
+	"snowflake":                       "❄️",
+	"pizza":                           "🍕",
+	"hamburger":                       "🍔",
+	"french_fries":                    "🍟",
+	"fries":                           "🍟",
+	"popcorn":                         "🍿",
+	"shortcake":                       "🍰",
+	"cake":                            "🍰",
+	"hot_beverage":                    "☕",
+	"coffee":                          "☕",
+	"beer_mug":                        "🍺",
+	"beer":                            "🍺",
+	"clinking_beer_mugs":              "🍻",
+	"beers":                           "🍻",
+	"wine_glass":                      "🍷",
+	"dog_face":                        "🐶",
+	"dog":                             "🐶",
+	"cat_face":                        "🐱",
+	"cat":                             "🐱",
+	"frog":                            "🐸",
+	"snake":                           "🐍",
+	"crab":                            "🦀",
+	"penguin":                         "🐧",
+	"check_mark_button":               "✅",
+	"white_check_mark":                "✅",
+	"cross_mark":                      "❌",
+	"x":                               "❌",
+	"red_question_mark":               "❓",
+	"question":                        "❓",
+	"red_exclamation_mark":            "❗",
+	"exclamation":                     "❗",
+	"warning":                         "⚠️",
+	"ok_button":                       "🆗",
+	"ok":                              "🆗",
+	"red_circle":                      "🔴",
+	"green_circle":                    "🟢",
+	"blue_circle":                     "🔵",
+	"up_arrow":                        "⬆️",
+	"arrow_up":                        "⬆️",
+	"down_arrow":                      "⬇️",
+	"arrow_down":                      "⬇️",
+	"flag_japan":                      "🇯🇵",
+	"jp":                              "🇯🇵",
+	"flag_united_states":              "🇺🇸",
+	"us":                              "🇺🇸",
+	"flag_brazil":                     "🇧🇷",
+	"brazil":                          "🇧🇷",
+	"flag_united_kingdom":             "🇬🇧",
+	"gb":                              "🇬🇧",
+	"uk":                              "🇬🇧",
+}
+
+// LookupEmoji returns the Unicode sequence of a standard emoji shortcut, with
+// or without colons, including skin-tone variants such as
+// ":thumbs_up_dark_skin_tone:" or ":+1::skin-tone-6:".
+func LookupEmoji(shortcut string) (string, bool) {
+	name := strings.Trim(shortcut, ":")
+
+	modifier := ""
+	for _, tone := range skinToneSuffixes {
+		if strings.HasSuffix(name, tone.suffix) {
+			name = strings.TrimSuffix(name, tone.suffix)
+			modifier = tone.modifier
+			break
+		}
+	}
+
+	glyph, ok := standardEmoji[name]
+	if !ok {
+		return "", false
+	}
+	if modifier != "" {
+		// The modifier replaces the emoji presentation selector
+		glyph = strings.TrimSuffix(glyph, "\uFE0F") + modifier
+	}
+	return glyph, true
+}
+
+// resolveEmojiUnicode finds the Unicode sequence of a standard emoji. Its ID is
+// usually the sequence already; otherwise the codepoints are read from the ID
+// or the image name, and the shortcuts are looked up last.
+func resolveEmojiUnicode(emoji *types.MessageEmoji) string {
+	if emoji.IsCustomEmoji {
+		return ""
+	}
+
+	if isEmojiSequence(emoji.EmojiId) {
+		return emoji.EmojiId
+	}
+	if m := regexEmojiCodepoints.FindStringSubmatch(emoji.EmojiId); m != nil {
+		if glyph := decodeCodepoints(m[1]); glyph != "" {
+			return glyph
+		}
+	}
+	for _, thumb := range emoji.Image.Thumbnails {
+		if m := regexEmojiImageURL.FindStringSubmatch(thumb.URL); m != nil {
+			if glyph := decodeCodepoints(m[1]); glyph != "" {
+				return glyph
+			}
+		}
+	}
+	for _, shortcut := range emoji.Shortcuts {
+		if glyph, ok := LookupEmoji(shortcut); ok {
+			return glyph
+		}
+	}
+	return ""
+}
+
+// isEmojiSequence reports whether s is made of non-ASCII characters only
+func isEmojiSequence(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeCodepoints(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' }) {
+		n, err := strconv.ParseUint(part, 16, 32)
+		if err != nil || n > 0x10FFFF {
+			return ""
+		}
+		b.WriteRune(rune(n))
+	}
+	return b.String()
+}
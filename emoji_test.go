@@ -0,0 +1,98 @@
+package youtubechat
+
+import (
+	"testing"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+func TestLookupEmoji(t *testing.T) {
+	tests := []struct {
+		shortcut string
+		expected string
+	}{
+		{":clapping_hands:", "👏"},
+		{":clap:", "👏"},
+		{"thumbs_up", "👍"},
+		{":+1:", "👍"},
+		{":face_with_tears_of_joy:", "😂"},
+		{":red_heart:", "❤️"},
+		{":clapping_hands_dark_skin_tone:", "👏🏿"},
+		{":clapping_hands_medium_light_skin_tone:", "👏🏼"},
+		{":thumbs_up_light_skin_tone:", "👍🏻"},
+		{":clap::skin-tone-4:", "👏🏽"},
+		{":victory_hand_medium_skin_tone:", "✌🏽"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shortcut, func(t *testing.T) {
+			glyph, ok := LookupEmoji(tt.shortcut)
+			if !ok {
+				t.Fatalf("Expected %s to be known", tt.shortcut)
+			}
+			if glyph != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, glyph)
+			}
+		})
+	}
+
+	if _, ok := LookupEmoji(":customEmoji:"); ok {
+		t.Error("Expected unknown shortcut")
+	}
+}
+
+func TestResolveEmojiUnicode(t *testing.T) {
+	image := func(url string) types.MessageEmoji {
+		var emoji types.MessageEmoji
+		emoji.EmojiId = "opaqueId"
+		emoji.Image.Thumbnails = []types.Thumbnail{{URL: url}}
+		return emoji
+	}
+
+	tests := []struct {
+		name     string
+		emoji    types.MessageEmoji
+		expected string
+	}{
+		{"Glyph ID", types.MessageEmoji{EmojiId: "👏🏿"}, "👏🏿"},
+		{"Codepoint ID", types.MessageEmoji{EmojiId: "1f44f-1f3ff"}, "👏🏿"},
+		{"Prefixed codepoint ID", types.MessageEmoji{EmojiId: "u1f600"}, "😀"},
+		{"Image name", image("https://www.youtube.com/s/gaming/emoji/0f0cae22/emoji_u1f44f_1f3ff.svg"), "👏🏿"},
+		{"Noto image", image("https://fonts.gstatic.com/s/e/notoemoji/15.0/1f525/72.png"), "🔥"},
+		{"Shortcut", types.MessageEmoji{EmojiId: "opaqueId", Shortcuts: []string{":fire:"}}, "🔥"},
+		{"Custom", types.MessageEmoji{EmojiId: "UCxx/abc", Shortcuts: []string{":fire:"}, IsCustomEmoji: true}, ""},
+		{"Unknown", types.MessageEmoji{EmojiId: "opaqueId", Shortcuts: []string{":nope:"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if glyph := resolveEmojiUnicode(&tt.emoji); glyph != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, glyph)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	items := []types.MessageItem{
+		{Text: "hi "},
+		{EmojiItem: &types.EmojiItem{EmojiText: "opaqueId", Unicode: "👏", Shortcuts: []string{":clap:"}}},
+		{Text: " "},
+		{EmojiItem: &types.EmojiItem{EmojiText: ":customEmoji:", IsCustomEmoji: true, Shortcuts: []string{":customEmoji:"}}},
+	}
+
+	tests := []struct {
+		style    types.EmojiStyle
+		expected string
+	}{
+		{types.EmojiAsParsed, "hi opaqueId :customEmoji:"},
+		{types.EmojiAsUnicode, "hi 👏 :customEmoji:"},
+		{types.EmojiAsShortcode, "hi :clap: :customEmoji:"},
+	}
+
+	for _, tt := range tests {
+		if text := types.PlainText(items, tt.style); text != tt.expected {
+			t.Errorf("Style %d: expected %q, got %q", tt.style, tt.expected, text)
+		}
+	}
+}
@@ -1,18 +1,31 @@
 package youtubechat
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/DiegPS/youtube-chat/types"
 )
 
 type LiveChat struct {
-	// Events exposed as channels
-	ChatChan  chan types.ChatItem
-	ErrorChan chan error
-	StartChan chan string
-	EndChan   chan string
+	// Events exposed as channels. EventChan carries every event of the other
+	// channels in the order YouTube sent them: use it when an event may refer
+	// to an earlier one, e.g. a deletion to a chat item. It alone carries the
+	// author-less items posted by YouTube, those with System set.
+	EventChan    chan types.Event
+	ChatChan     chan types.ChatItem
+	DeleteChan   chan types.Deletion
+	ReplaceChan  chan types.Replacement
+	TickerChan   chan types.Ticker
+	PollChan     chan types.PollEvent
+	BannerChan   chan types.BannerEvent
+	RedirectChan chan types.Redirect
+	CustomChan   chan types.CustomEvent
+	ErrorChan    chan error
+	StartChan    chan string
+	EndChan      chan string
 
 	liveID   string
 	observer *time.Ticker
@@ -21,19 +34,88 @@ type LiveChat struct {
 	id       types.YoutubeId
 	stopChan chan struct{}
 	running  bool
+	chatMode types.ChatMode
+	backlog  bool
+	polls    map[string]types.Poll // open polls by ID
+	keepRaw  bool
+	parsers  map[string]RendererParser
 
-	// Fetch replacement for testing
+	diagnostics diagnostics
+
+	// Fetch replacement for testing. FetchChatFunc, when set, is used instead
+	// of FetchActionsFunc and only yields chat items.
 	FetchLivePageFunc func(types.YoutubeId) (types.FetchOptions, error)
 	FetchChatFunc     func(types.FetchOptions) ([]types.ChatItem, string, error)
+	FetchActionsFunc  func(types.FetchOptions) (types.ChatActions, string, error)
+	FetchBacklogFunc  func(types.FetchOptions) (types.ChatActions, string, error)
+}
+
+// Option configures a LiveChat created by NewLiveChat
+type Option func(*LiveChat)
+
+// WithChatMode selects the "Top chat" or "Live chat" view.
+// The default keeps whichever continuation the page lists first.
+func WithChatMode(mode types.ChatMode) Option {
+	return func(lc *LiveChat) {
+		lc.chatMode = mode
+	}
+}
+
+// WithInnertubeBootstrap obtains the chat continuation from the innertube next
+// endpoint, falling back to scraping the live page when that fails.
+func WithInnertubeBootstrap() Option {
+	return func(lc *LiveChat) {
+		lc.FetchLivePageFunc = FetchInnertubeOptions
+	}
+}
+
+// WithBacklog emits the messages already shown when the chat is opened,
+// flagged IsBacklog, before any live item. Backlog deletions, tickers and
+// banners are delivered first too but have no flag of their own.
+func WithBacklog() Option {
+	return func(lc *LiveChat) {
+		lc.backlog = true
+	}
+}
+
+// WithRawActions keeps the JSON of each action on the events decoded from it
+func WithRawActions() Option {
+	return func(lc *LiveChat) {
+		lc.keepRaw = true
+	}
+}
+
+// RendererParser decodes a renderer or action the library does not handle.
+// It receives the JSON under the key it was registered for.
+type RendererParser func(raw json.RawMessage) (any, error)
+
+// WithRendererParser registers a parser for an unhandled renderer key, such as
+// "liveChatFooRenderer" in an added or replaced item or banner, or for an action key.
+// Its results are delivered on CustomChan; its errors on ErrorChan.
+func WithRendererParser(key string, parser RendererParser) Option {
+	return func(lc *LiveChat) {
+		if lc.parsers == nil {
+			lc.parsers = map[string]RendererParser{}
+		}
+		lc.parsers[key] = parser
+	}
 }
 
-func NewLiveChat(id types.YoutubeId, intervalMs int) (*LiveChat, error) {
-	if id.ChannelID == "" && id.LiveID == "" && id.Handle == "" {
-		return nil, errors.New("Required channelId or liveId or handle.")
+func NewLiveChat(id types.YoutubeId, intervalMs int, opts ...Option) (*LiveChat, error) {
+	if id.ChannelID == "" && id.LiveID == "" && id.Handle == "" && id.CustomURL == "" {
+		return nil, errors.New("Required channelId or liveId or handle or customUrl.")
 	}
 
 	lc := &LiveChat{
+		EventChan:         make(chan types.Event, 100),
 		ChatChan:          make(chan types.ChatItem, 100),
+		DeleteChan:        make(chan types.Deletion, 100),
+		ReplaceChan:       make(chan types.Replacement, 100),
+		TickerChan:        make(chan types.Ticker, 100),
+		PollChan:          make(chan types.PollEvent, 100),
+		BannerChan:        make(chan types.BannerEvent, 100),
+		RedirectChan:      make(chan types.Redirect, 100),
+		CustomChan:        make(chan types.CustomEvent, 100),
 		ErrorChan:         make(chan error, 10),
 		StartChan:         make(chan string, 1),
 		EndChan:           make(chan string, 1),
@@ -41,8 +123,10 @@ func NewLiveChat(id types.YoutubeId, intervalMs int) (*LiveChat, error) {
 		interval:          time.Duration(intervalMs) * time.Millisecond,
 		stopChan:          make(chan struct{}),
 		FetchLivePageFunc: FetchLivePage,
-		FetchChatFunc:     FetchChat,
+		polls:             map[string]types.Poll{},
 	}
+	lc.FetchActionsFunc = lc.fetchChatActions
+	lc.FetchBacklogFunc = lc.fetchLiveChatPage
 
 	if lc.interval == 0 {
 		lc.interval = 1000 * time.Millisecond
@@ -52,6 +136,10 @@ func NewLiveChat(id types.YoutubeId, intervalMs int) (*LiveChat, error) {
 		lc.liveID = id.LiveID
 	}
 
+	for _, opt := range opts {
+		opt(lc)
+	}
+
 	return lc, nil
 }
 
@@ -65,11 +153,33 @@ func (lc *LiveChat) Start() error {
 		return err
 	}
 
+	options, err = SelectChatMode(options, lc.chatMode)
+	if err != nil {
+		return err
+	}
+
+	var backlog types.ChatActions
+	if lc.backlog {
+		actions, continuation, err := lc.FetchBacklogFunc(options)
+		if err == nil && continuation == "" {
+			// Without it the live continuation would repeat or skip messages
+			err = errors.New("no continuation in live_chat page, backlog dropped")
+		}
+		if err != nil {
+			// The backlog is best effort, observation works without it
+			lc.emitError(err)
+		} else {
+			backlog = actions
+			options.Continuation = continuation
+		}
+	}
+
 	lc.liveID = options.LiveID
 	lc.options = &options
 
 	lc.running = true
 	lc.stopChan = make(chan struct{})
+	lc.polls = map[string]types.Poll{}
 
 	lc.observer = time.NewTicker(lc.interval)
 
@@ -78,6 +188,8 @@ func (lc *LiveChat) Start() error {
 	default:
 	}
 
+	lc.emitActions(backlog)
+
 	go lc.loop()
 
 	return nil
@@ -98,6 +210,23 @@ func (lc *LiveChat) Stop(reason string) {
 	}
 }
 
+// fetchChatActions and fetchLiveChatPage are the default fetch functions,
+// keeping the action JSON with WithRawActions
+func (lc *LiveChat) fetchChatActions(options types.FetchOptions) (types.ChatActions, string, error) {
+	return fetchChatActions(options, lc.parseOptions()...)
+}
+
+func (lc *LiveChat) fetchLiveChatPage(options types.FetchOptions) (types.ChatActions, string, error) {
+	return fetchLiveChatPage(options, lc.parseOptions()...)
+}
+
+func (lc *LiveChat) parseOptions() []ParseOption {
+	if lc.keepRaw {
+		return []ParseOption{WithRawJSON()}
+	}
+	return nil
+}
+
 func (lc *LiveChat) loop() {
 	for {
 		select {
@@ -117,23 +246,182 @@ func (lc *LiveChat) execute() {
 		return
 	}
 
-	items, continuation, err := lc.FetchChatFunc(*lc.options)
+	var actions types.ChatActions
+	var continuation string
+	var err error
+	if lc.FetchChatFunc != nil {
+		actions.Items, continuation, err = lc.FetchChatFunc(*lc.options)
+	} else {
+		actions, continuation, err = lc.FetchActionsFunc(*lc.options)
+	}
 	if err != nil {
 		lc.emitError(err)
 		return
 	}
 
-	for _, item := range items {
-		select {
-		case lc.ChatChan <- item:
-		default:
-			// If channel full, drop? Or block?
-			// Blocking might stall the ticker. Drop if full is safer for realtime.
-			// Or make buffer large enough.
+	lc.emitActions(actions)
+
+	lc.options.Continuation = continuation
+}
+
+// emitActions delivers the events in the order of the actions on EventChan and
+// on the channel of their type. Events are copies: the fetched slices are left
+// untouched.
+func (lc *LiveChat) emitActions(actions types.ChatActions) {
+	now := time.Now()
+	var unhandled []types.UnhandledAction
+
+	for _, event := range actions.Events() {
+		switch e := event.(type) {
+		case types.ChatItem:
+			lc.stampItem(&e, now)
+			event = e
+			if e.System == nil {
+				// System items have no author, ChatChan keeps to messages
+				emit(lc.ChatChan, e)
+			}
+		case types.Deletion:
+			emit(lc.DeleteChan, e)
+		case types.Replacement:
+			lc.stampItem(&e.Item, now)
+			event = e
+			emit(lc.ReplaceChan, e)
+		case types.Ticker:
+			e.LinkedItem = lc.stampCopy(e.LinkedItem, now)
+			event = e
+			emit(lc.TickerChan, e)
+		case types.PollEvent:
+			var ok bool
+			if e, ok = lc.trackPoll(e); !ok {
+				continue
+			}
+			event = e
+			emit(lc.PollChan, e)
+		case types.BannerEvent:
+			e.Banner.Item = lc.stampCopy(e.Banner.Item, now)
+			event = e
+			emit(lc.BannerChan, e)
+		case types.Redirect:
+			e = lc.fillRedirect(e)
+			event = e
+			emit(lc.RedirectChan, e)
+		case types.UnhandledAction:
+			custom, ok := lc.parseCustom(e)
+			if !ok {
+				unhandled = append(unhandled, e)
+				continue
+			}
+			if custom == nil {
+				continue
+			}
+			event = *custom
+			emit(lc.CustomChan, *custom)
 		}
+		emit(lc.EventChan, event)
 	}
 
-	lc.options.Continuation = continuation
+	lc.recordUnhandled(unhandled)
+}
+
+// stampItem sets the receive time and, for live streams with a known start,
+// the offset from the start
+func (lc *LiveChat) stampItem(item *types.ChatItem, now time.Time) {
+	item.ReceivedAt = now
+	if item.HasOffset || item.IsTimestampSynthesized || lc.options == nil || lc.options.StartTime.IsZero() {
+		return
+	}
+	item.Offset = item.Timestamp.Sub(lc.options.StartTime)
+	item.HasOffset = true
+}
+
+// stampCopy stamps a copy of an item referenced by another event
+func (lc *LiveChat) stampCopy(item *types.ChatItem, now time.Time) *types.ChatItem {
+	if item == nil {
+		return nil
+	}
+	stamped := *item
+	lc.stampItem(&stamped, now)
+	return &stamped
+}
+
+// parseCustom runs the parser registered for the renderer, or else for the
+// action. It reports false when there is none; the event is nil when the
+// parser failed.
+func (lc *LiveChat) parseCustom(action types.UnhandledAction) (*types.CustomEvent, bool) {
+	key, input := action.RendererKey, action.Renderer
+	parser, ok := lc.parsers[key]
+	if key == "" || !ok {
+		// A parser for the action gets the whole action value
+		key = action.Key
+		_, input = firstKey(action.Raw, "clickTrackingParams")
+		if parser, ok = lc.parsers[key]; !ok {
+			return nil, false
+		}
+	}
+
+	value, err := parser(input)
+	if err != nil {
+		lc.emitError(fmt.Errorf("%s: %w", key, err))
+		return nil, true
+	}
+
+	event := types.CustomEvent{Key: key, Value: value}
+	if lc.keepRaw {
+		event.Raw = action.Raw
+	}
+	return &event, true
+}
+
+// fillRedirect sets the observed channel as the target of a raid or the
+// source of an outgoing redirect
+func (lc *LiveChat) fillRedirect(redirect types.Redirect) types.Redirect {
+	if lc.options == nil {
+		return redirect
+	}
+	switch redirect.Kind {
+	case types.RedirectIncoming:
+		redirect.Target = lc.options.Channel
+	case types.RedirectOutgoing:
+		redirect.Source = lc.options.Channel
+	}
+	return redirect
+}
+
+// trackPoll keeps the last state of open polls so that updates keep their panel
+// and the end of a poll carries its final results. Closed panels that were not
+// showing a known poll are dropped, as is the end of a poll first seen through
+// an update, whose panel is unknown.
+func (lc *LiveChat) trackPoll(event types.PollEvent) (types.PollEvent, bool) {
+	switch event.Kind {
+	case types.PollCreated:
+		lc.polls[event.Poll.ID] = event.Poll
+	case types.PollUpdated:
+		if prev, ok := lc.polls[event.Poll.ID]; ok {
+			event.Poll.PanelID = prev.PanelID
+		}
+		lc.polls[event.Poll.ID] = event.Poll
+	case types.PollEnded:
+		for id, poll := range lc.polls {
+			if poll.PanelID == event.Poll.PanelID {
+				poll.State = types.PollClosed
+				event.Poll = poll
+				delete(lc.polls, id)
+				return event, true
+			}
+		}
+		return event, false
+	}
+	return event, true
+}
+
+func emit[T any](ch chan T, v T) {
+	select {
+	case ch <- v:
+	default:
+		// If channel full, drop? Or block?
+		// Blocking might stall the ticker. Drop if full is safer for realtime.
+		// Or make buffer large enough.
+	}
 }
 
 func (lc *LiveChat) emitError(err error) {
@@ -1,7 +1,14 @@
 package youtubechat
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -93,6 +100,39 @@ func TestStart(t *testing.T) {
 	lc.Stop("test")
 }
 
+func TestStartWithChatMode(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50, WithChatMode(types.ChatModeLive))
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) {
+		opts := mockOptions
+		opts.TopChatContinuation = "top"
+		opts.LiveChatContinuation = "live"
+		return opts, nil
+	}
+
+	used := make(chan string, 1)
+	lc.FetchChatFunc = func(opts types.FetchOptions) ([]types.ChatItem, string, error) {
+		select {
+		case used <- opts.Continuation:
+		default:
+		}
+		return nil, opts.Continuation, nil
+	}
+
+	if err := lc.Start(); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	defer lc.Stop("done")
+
+	select {
+	case cont := <-used:
+		if cont != "live" {
+			t.Errorf("Expected live chat continuation, got %s", cont)
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("Timeout waiting for FetchChat")
+	}
+}
+
 func TestStartSecondTime(t *testing.T) {
 	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 100)
 	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
@@ -161,6 +201,423 @@ func TestOnChat(t *testing.T) {
 	}
 }
 
+func TestOnDelete(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		return types.ChatActions{Deletions: []types.Deletion{{TargetItemID: "id"}}}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case deletion := <-lc.DeleteChan:
+		if deletion.TargetItemID != "id" {
+			t.Errorf("Expected deletion of 'id', got %s", deletion.TargetItemID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for DeleteChan")
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		var actions types.ChatActions
+		actions.Add(types.ChatItem{ID: "first"})
+		actions.Add(types.Deletion{TargetItemID: "first"})
+		actions.Add(types.ChatItem{ID: "second"})
+		return actions, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case event := <-lc.EventChan:
+			switch e := event.(type) {
+			case types.ChatItem:
+				got = append(got, "item "+e.ID)
+			case types.Deletion:
+				got = append(got, "deletion "+e.TargetItemID)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for EventChan")
+		}
+	}
+
+	expected := []string{"item first", "deletion first", "item second"}
+	if strings.Join(got, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestOnEvent_System(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+
+	calls := 0
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		calls++
+		var actions types.ChatActions
+		if calls == 1 {
+			actions.Add(types.ChatItem{ID: "slowMode", System: &types.SystemMessage{Kind: types.SystemSlowMode}})
+			actions.Add(mockChatItems[0])
+		}
+		return actions, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case chat := <-lc.ChatChan:
+		if chat.System != nil || chat.ID != mockChatItems[0].ID {
+			t.Errorf("Expected only messages with an author on ChatChan, got %+v", chat)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for ChatChan")
+	}
+
+	select {
+	case event := <-lc.EventChan:
+		if item, ok := event.(types.ChatItem); !ok || item.System == nil || item.ID != "slowMode" {
+			t.Errorf("Expected the system item first on EventChan, got %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for EventChan")
+	}
+}
+
+func TestOnReplace(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		replacement := types.Replacement{TargetItemID: "id", Item: mockChatItems[0]}
+		return types.ChatActions{Replacements: []types.Replacement{replacement}}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case replacement := <-lc.ReplaceChan:
+		if replacement.TargetItemID != "id" {
+			t.Errorf("Expected replacement of 'id', got %s", replacement.TargetItemID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for ReplaceChan")
+	}
+}
+
+func TestOnTicker(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		ticker := types.Ticker{ID: "tickerId", Kind: types.TickerPaidMessage}
+		return types.ChatActions{Tickers: []types.Ticker{ticker}}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case ticker := <-lc.TickerChan:
+		if ticker.ID != "tickerId" {
+			t.Errorf("Expected ticker 'tickerId', got %s", ticker.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for TickerChan")
+	}
+}
+
+func TestOnPoll(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+
+	calls := 0
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		calls++
+		switch calls {
+		case 1:
+			return types.ChatActions{Polls: []types.PollEvent{
+				{Kind: types.PollCreated, Poll: types.Poll{ID: "pollId", PanelID: "panelId", State: types.PollOpen}},
+				{Kind: types.PollUpdated, Poll: types.Poll{ID: "pollId", TotalVotes: 10, State: types.PollOpen}},
+			}}, "continuation", nil
+		case 2:
+			return types.ChatActions{Polls: []types.PollEvent{
+				{Kind: types.PollEnded, Poll: types.Poll{PanelID: "otherPanel", State: types.PollClosed}},
+				{Kind: types.PollEnded, Poll: types.Poll{PanelID: "panelId", State: types.PollClosed}},
+			}}, "continuation", nil
+		}
+		return types.ChatActions{}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	var events []types.PollEvent
+	for len(events) < 3 {
+		select {
+		case event := <-lc.PollChan:
+			events = append(events, event)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for PollChan")
+		}
+	}
+
+	if events[1].Poll.PanelID != "panelId" {
+		t.Errorf("Expected update to keep the panel, got %s", events[1].Poll.PanelID)
+	}
+	ended := events[2]
+	if ended.Kind != types.PollEnded || ended.Poll.ID != "pollId" || ended.Poll.TotalVotes != 10 {
+		t.Errorf("Expected final results of pollId, got %+v", ended)
+	}
+}
+
+func TestOnPoll_FirstSeenInUpdate(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+
+	calls := 0
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		calls++
+		switch calls {
+		case 1:
+			return types.ChatActions{Polls: []types.PollEvent{
+				{Kind: types.PollUpdated, Poll: types.Poll{ID: "pollId", TotalVotes: 10, State: types.PollOpen}},
+			}}, "continuation", nil
+		case 2:
+			// The panel of pollId is unknown, nothing ties this close to it
+			return types.ChatActions{Polls: []types.PollEvent{
+				{Kind: types.PollEnded, Poll: types.Poll{PanelID: "panelId", State: types.PollClosed}},
+				{Kind: types.PollCreated, Poll: types.Poll{ID: "otherPoll", PanelID: "otherPanel", State: types.PollOpen}},
+			}}, "continuation", nil
+		case 3:
+			return types.ChatActions{Polls: []types.PollEvent{
+				{Kind: types.PollEnded, Poll: types.Poll{PanelID: "otherPanel", State: types.PollClosed}},
+			}}, "continuation", nil
+		}
+		return types.ChatActions{}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	var events []types.PollEvent
+	for len(events) < 3 {
+		select {
+		case event := <-lc.PollChan:
+			events = append(events, event)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for PollChan")
+		}
+	}
+
+	if events[0].Kind != types.PollUpdated || events[0].Poll.PanelID != "" {
+		t.Errorf("Expected update of pollId without a panel, got %+v", events[0])
+	}
+	if events[1].Kind != types.PollCreated || events[1].Poll.ID != "otherPoll" {
+		t.Errorf("Expected unrelated close to be dropped, got %+v", events[1])
+	}
+	if ended := events[2]; ended.Kind != types.PollEnded || ended.Poll.ID != "otherPoll" {
+		t.Errorf("Expected final results of otherPoll, got %+v", ended)
+	}
+}
+
+func TestOnBanner(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		event := types.BannerEvent{Kind: types.BannerRemoved, Banner: types.Banner{ID: "bannerId"}}
+		return types.ChatActions{Banners: []types.BannerEvent{event}}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case event := <-lc.BannerChan:
+		if event.Banner.ID != "bannerId" {
+			t.Errorf("Expected banner 'bannerId', got %s", event.Banner.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for BannerChan")
+	}
+}
+
+func TestOnRedirect(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) {
+		options := mockOptions
+		options.Channel = types.Channel{ID: "channelId", Handle: "@handle"}
+		return options, nil
+	}
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		raid := types.Redirect{ID: "raidId", Kind: types.RedirectIncoming, Source: types.Channel{Handle: "@raider"}}
+		return types.ChatActions{Redirects: []types.Redirect{raid}}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case redirect := <-lc.RedirectChan:
+		if redirect.Source.Handle != "@raider" || redirect.Target.ID != "channelId" {
+			t.Errorf("Unexpected redirect %+v", redirect)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for RedirectChan")
+	}
+}
+
+func TestOnCustom(t *testing.T) {
+	type foo struct {
+		ID string `json:"id"`
+	}
+	parseFoo := func(raw json.RawMessage) (any, error) {
+		var f foo
+		err := json.Unmarshal(raw, &f)
+		return f, err
+	}
+
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50, WithRendererParser("liveChatFooRenderer", parseFoo))
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		unhandled := types.UnhandledAction{
+			Key:         "addChatItemAction",
+			RendererKey: "liveChatFooRenderer",
+			Renderer:    json.RawMessage(`{"id":"fooId"}`),
+			Raw:         json.RawMessage(`{"addChatItemAction":{"item":{"liveChatFooRenderer":{"id":"fooId"}}}}`),
+		}
+		return types.ChatActions{Unhandled: []types.UnhandledAction{unhandled}}, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case event := <-lc.CustomChan:
+		if event.Key != "liveChatFooRenderer" || event.Value.(foo).ID != "fooId" {
+			t.Errorf("Unexpected custom event %+v", event)
+		}
+		if event.Raw != nil {
+			t.Error("Expected raw JSON dropped without WithRawActions")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for CustomChan")
+	}
+}
+
+func TestWithRawActions(t *testing.T) {
+	response, err := os.ReadFile(filepath.Join("testdata", "get_live_chat.normal.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(response)
+	}))
+	defer ts.Close()
+
+	origBaseURL := BaseURL
+	BaseURL = ts.URL
+	defer func() { BaseURL = origBaseURL }()
+
+	for _, keep := range []bool{false, true} {
+		t.Run(fmt.Sprint(keep), func(t *testing.T) {
+			var opts []Option
+			if keep {
+				opts = append(opts, WithRawActions())
+			}
+			lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50, opts...)
+			lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+
+			lc.Start()
+			defer lc.Stop("done")
+
+			select {
+			case chat := <-lc.ChatChan:
+				if keep && !strings.Contains(string(chat.Raw), `"addChatItemAction"`) {
+					t.Errorf("Expected raw JSON kept, got %s", chat.Raw)
+				}
+				if !keep && chat.Raw != nil {
+					t.Errorf("Expected no raw JSON, got %s", chat.Raw)
+				}
+			case <-time.After(2 * time.Second):
+				t.Error("Timeout waiting for ChatChan")
+			}
+		})
+	}
+}
+
+func TestOnChat_Backlog(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50, WithBacklog())
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchBacklogFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		backlog := mockChatItems[0]
+		backlog.ID = "backlog"
+		backlog.IsBacklog = true
+		return types.ChatActions{Items: []types.ChatItem{backlog}}, "backlog-continuation", nil
+	}
+
+	used := make(chan string, 1)
+	lc.FetchChatFunc = func(opts types.FetchOptions) ([]types.ChatItem, string, error) {
+		select {
+		case used <- opts.Continuation:
+		default:
+		}
+		return mockChatItems, opts.Continuation, nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case chat := <-lc.ChatChan:
+		if chat.ID != "backlog" || !chat.IsBacklog {
+			t.Errorf("Expected backlog item first, got %s", chat.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for ChatChan")
+	}
+
+	select {
+	case cont := <-used:
+		if cont != "backlog-continuation" {
+			t.Errorf("Expected continuation after the backlog, got %s", cont)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for FetchChat")
+	}
+}
+
+func TestOnChat_BacklogWithoutContinuation(t *testing.T) {
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50, WithBacklog())
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) { return mockOptions, nil }
+	lc.FetchBacklogFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		return types.ChatActions{Items: []types.ChatItem{{ID: "backlog"}}}, "", nil
+	}
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		return types.ChatActions{}, opts.Continuation, nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case err := <-lc.ErrorChan:
+		if !strings.Contains(err.Error(), "no continuation") {
+			t.Errorf("Unexpected error %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for ErrorChan")
+	}
+}
+
 func TestOnError_FetchLivePage(t *testing.T) {
 	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 100)
 	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) {
@@ -194,3 +651,79 @@ func TestOnError_FetchChat(t *testing.T) {
 		t.Error("Timeout waiting for ErrorChan")
 	}
 }
+
+func TestStreamOffset(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) {
+		opts := mockOptions
+		opts.StartTime = start
+		return opts, nil
+	}
+	fetched := []types.ChatItem{mockChatItems[0]}
+	fetched[0].Timestamp = start.Add(10 * time.Minute)
+	lc.FetchChatFunc = func(opts types.FetchOptions) ([]types.ChatItem, string, error) {
+		return fetched, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	select {
+	case chat := <-lc.ChatChan:
+		if !chat.HasOffset || chat.Offset != 10*time.Minute {
+			t.Errorf("Expected offset 10m, got %v (%v)", chat.Offset, chat.HasOffset)
+		}
+		if chat.ReceivedAt.IsZero() || chat.Latency() < 50*time.Minute {
+			t.Errorf("Unexpected receive time %v, latency %v", chat.ReceivedAt, chat.Latency())
+		}
+		if fetched[0].HasOffset || !fetched[0].ReceivedAt.IsZero() {
+			t.Error("Expected the fetched items to be left untouched")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for ChatChan")
+	}
+}
+
+func TestStreamOffset_Nested(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	lc, _ := NewLiveChat(types.YoutubeId{ChannelID: "channelId"}, 50)
+	lc.FetchLivePageFunc = func(id types.YoutubeId) (types.FetchOptions, error) {
+		opts := mockOptions
+		opts.StartTime = start
+		return opts, nil
+	}
+	item := mockChatItems[0]
+	item.Timestamp = start.Add(10 * time.Minute)
+	linked, pinned := item, item
+	lc.FetchActionsFunc = func(opts types.FetchOptions) (types.ChatActions, string, error) {
+		var actions types.ChatActions
+		actions.Add(types.Replacement{TargetItemID: "placeholderId", Item: item})
+		actions.Add(types.Ticker{ID: "tickerId", LinkedItem: &linked})
+		actions.Add(types.BannerEvent{Kind: types.BannerAdded, Banner: types.Banner{ID: "bannerId", Item: &pinned}})
+		return actions, "continuation", nil
+	}
+
+	lc.Start()
+	defer lc.Stop("done")
+
+	var stamped []types.ChatItem
+	for len(stamped) < 3 {
+		select {
+		case replacement := <-lc.ReplaceChan:
+			stamped = append(stamped, replacement.Item)
+		case ticker := <-lc.TickerChan:
+			stamped = append(stamped, *ticker.LinkedItem)
+		case banner := <-lc.BannerChan:
+			stamped = append(stamped, *banner.Banner.Item)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for nested items")
+		}
+	}
+
+	for _, chat := range stamped {
+		if chat.ReceivedAt.IsZero() || !chat.HasOffset || chat.Offset != 10*time.Minute {
+			t.Errorf("Expected nested item stamped with offset 10m, got %v (%v)", chat.Offset, chat.HasOffset)
+		}
+	}
+}
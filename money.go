@@ -0,0 +1,184 @@
+package youtubechat
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+var (
+	regexMoneyNumber = regexp.MustCompile(`\d(?:[\d.,' ]*\d)?`)
+	regexISOCode     = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// currencySymbols maps the symbols YouTube prints in front of or after an
+// amount, depending on the viewer's locale, to ISO 4217 codes. Amounts may
+// also be prefixed by the bare code, e.g. "SGD 7.00". The whole text around the
+// number is looked up, so "RD$" and "$U" are never read as "$".
+var currencySymbols = map[string]string{
+	"$":    "USD",
+	"US$":  "USD",
+	"CA$":  "CAD",
+	"A$":   "AUD",
+	"AU$":  "AUD",
+	"NZ$":  "NZD",
+	"MX$":  "MXN",
+	"R$":   "BRL",
+	"NT$":  "TWD",
+	"HK$":  "HKD",
+	"S$":   "SGD",
+	"RD$":  "DOP",
+	"$U":   "UYU",
+	"S/":   "PEN",
+	"Q":    "GTQ",
+	"L":    "HNL",
+	"Bs":   "BOB",
+	"€":    "EUR",
+	"£":    "GBP",
+	"¥":    "JPY",
+	"￥":    "JPY",
+	"JP¥":  "JPY",
+	"CN¥":  "CNY",
+	"₩":    "KRW",
+	"￦":    "KRW",
+	"₹":    "INR",
+	"₨":    "PKR",
+	"Rs":   "PKR",
+	"৳":    "BDT",
+	"₱":    "PHP",
+	"PHP":  "PHP",
+	"₽":    "RUB",
+	"руб.": "RUB",
+	"₺":    "TRY",
+	"TL":   "TRY",
+	"₫":    "VND",
+	"฿":    "THB",
+	"₪":    "ILS",
+	"₴":    "UAH",
+	"грн.": "UAH",
+	"₦":    "NGN",
+	"₡":    "CRC",
+	"₲":    "PYG",
+	"₸":    "KZT",
+	"₾":    "GEL",
+	"₼":    "AZN",
+	"zł":   "PLN",
+	"Kč":   "CZK",
+	"Ft":   "HUF",
+	"lei":  "RON",
+	"лв.":  "BGN",
+	"RM":   "MYR",
+	"Rp":   "IDR",
+	"R":    "ZAR",
+	"Fr.":  "CHF",
+	"E£":   "EGP",
+	"د.إ.": "AED",
+	"ر.س.": "SAR",
+}
+
+// Currencies whose minor unit is not the usual two digits. A lone separator is
+// never a decimal one for zeroDecimals, and a three-digit group after it is
+// read as decimals for threeDecimals only.
+var (
+	zeroDecimals  = map[string]bool{"JPY": true, "KRW": true, "VND": true, "CLP": true, "ISK": true, "PYG": true, "UGX": true}
+	threeDecimals = map[string]bool{"BHD": true, "JOD": true, "KWD": true, "OMR": true, "TND": true, "IQD": true, "LYD": true}
+)
+
+// ParseMoney reads a display amount such as "¥1,000", "R$ 50,00", "CA$5.00"
+// or "1.234,50 €" into a Money. Display is always kept; an error is returned
+// when the number or the currency cannot be recognized, e.g. for "kr", which
+// several currencies share.
+func ParseMoney(display string) (types.Money, error) {
+	money := types.Money{Display: display}
+
+	s := strings.Map(func(r rune) rune {
+		switch r {
+		case '\u00a0', '\u202f', '\u2009':
+			return ' '
+		case '\u2019':
+			return '\''
+		case '\u200e', '\u200f', '\u061c':
+			// Direction marks around Arabic symbols
+			return -1
+		}
+		return r
+	}, display)
+
+	loc := regexMoneyNumber.FindStringIndex(s)
+	if loc == nil {
+		return money, fmt.Errorf("no amount in %q", display)
+	}
+	symbol := strings.TrimSpace(s[:loc[0]] + " " + s[loc[1]:])
+
+	currency := ""
+	if regexISOCode.MatchString(symbol) {
+		currency = symbol
+	} else if code, ok := currencySymbols[symbol]; ok {
+		currency = code
+	}
+
+	micros, err := parseMicros(s[loc[0]:loc[1]], currency)
+	if err != nil {
+		return money, fmt.Errorf("invalid amount %q: %w", display, err)
+	}
+	money.Micros = micros
+
+	if currency == "" {
+		return money, fmt.Errorf("unknown currency %q in %q", symbol, display)
+	}
+	money.Currency = currency
+
+	return money, nil
+}
+
+// parseMicros reads a number written with any grouping and decimal separator.
+// When both '.' and ',' appear the last one is the decimal separator; a single
+// separator followed by three digits is grouping unless the currency has three
+// decimals.
+func parseMicros(number string, currency string) (int64, error) {
+	number = strings.NewReplacer(" ", "", "'", "").Replace(number)
+
+	decimal := -1
+	lastDot := strings.LastIndexByte(number, '.')
+	lastComma := strings.LastIndexByte(number, ',')
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = max(lastDot, lastComma)
+	case lastDot >= 0 || lastComma >= 0:
+		sep := max(lastDot, lastComma)
+		if strings.Count(number, number[sep:sep+1]) == 1 {
+			digits := len(number) - sep - 1
+			if digits != 3 && !zeroDecimals[currency] || threeDecimals[currency] {
+				decimal = sep
+			}
+		}
+	}
+
+	whole, fraction := number, ""
+	if decimal >= 0 {
+		whole, fraction = number[:decimal], number[decimal+1:]
+	}
+	whole = strings.NewReplacer(".", "", ",", "").Replace(whole)
+	if strings.ContainsAny(fraction, ".,") {
+		return 0, fmt.Errorf("misplaced separator")
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(fraction) > 6 {
+		fraction = fraction[:6]
+	}
+	fraction += strings.Repeat("0", 6-len(fraction))
+	micros, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return units*1000000 + micros, nil
+}
@@ -0,0 +1,127 @@
+package youtubechat
+
+import (
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		display  string
+		currency string
+		micros   int64
+	}{
+		// English locales
+		{"$5.00", "USD", 5000000},
+		{"$1,000.00", "USD", 1000000000},
+		{"US$2.50", "USD", 2500000},
+		{"CA$5.00", "CAD", 5000000},
+		{"A$10.00", "AUD", 10000000},
+		{"NZ$20.00", "NZD", 20000000},
+		{"MX$100.00", "MXN", 100000000},
+		{"NT$75.00", "TWD", 75000000},
+		{"HK$40.00", "HKD", 40000000},
+		{"S$7.00", "SGD", 7000000},
+		{"RD$100.00", "DOP", 100000000},
+		{"$U 100", "UYU", 100000000},
+		{"S/ 20.00", "PEN", 20000000},
+		{"Q50.00", "GTQ", 50000000},
+		{"L 100.00", "HNL", 100000000},
+		{"Bs 10,00", "BOB", 10000000},
+		{"£2.00", "GBP", 2000000},
+		{"€5.00", "EUR", 5000000},
+		{"₹199.00", "INR", 199000000},
+		{"₹1,00,000.00", "INR", 100000000000},
+		{"₨500", "PKR", 500000000},
+		{"Rs 500.00", "PKR", 500000000},
+		{"৳500.00", "BDT", 500000000},
+		{"₱100.00", "PHP", 100000000},
+		{"PHP 100.00", "PHP", 100000000},
+		{"SGD 7.00", "SGD", 7000000},
+		{"CHF 5.00", "CHF", 5000000},
+		{"PLN 20.00", "PLN", 20000000},
+		{"SEK 50.00", "SEK", 50000000},
+		{"ARS 1,500.00", "ARS", 1500000000},
+
+		// Zero-decimal currencies
+		{"¥1,000", "JPY", 1000000000},
+		{"￥1,000", "JPY", 1000000000},
+		{"￥90", "JPY", 90000000},
+		{"JP¥500", "JPY", 500000000},
+		{"₩1,000", "KRW", 1000000000},
+		{"₩50,000", "KRW", 50000000000},
+		{"CN¥30.00", "CNY", 30000000},
+		{"CLP 1.000", "CLP", 1000000000},
+		{"₫20.000", "VND", 20000000000},
+
+		// Comma decimals and trailing symbols
+		{"R$ 50,00", "BRL", 50000000},
+		{"R$ 1.000,00", "BRL", 1000000000},
+		{"R$5,00", "BRL", 5000000},
+		{"5,00 €", "EUR", 5000000},
+		{"1.234,50 €", "EUR", 1234500000},
+		{"1 234,50 €", "EUR", 1234500000},
+		{"1 000,00 ₽", "RUB", 1000000000},
+		{"100,00 руб.", "RUB", 100000000},
+		{"20,00 zł", "PLN", 20000000},
+		{"100,00 Kč", "CZK", 100000000},
+		{"1 500 Ft", "HUF", 1500000000},
+		{"25,00 lei", "RON", 25000000},
+		{"10,00 лв.", "BGN", 10000000},
+		{"₺50,00", "TRY", 50000000},
+		{"50,00 TL", "TRY", 50000000},
+		{"200,00 грн.", "UAH", 200000000},
+		{"CHF 1'000.00", "CHF", 1000000000},
+		{"CHF 1’000.00", "CHF", 1000000000},
+
+		// Other symbols
+		{"RM20.00", "MYR", 20000000},
+		{"Rp 15.000", "IDR", 15000000000},
+		{"R 100,00", "ZAR", 100000000},
+		{"R100.00", "ZAR", 100000000},
+		{"฿100.00", "THB", 100000000},
+		{"₪20.00", "ILS", 20000000},
+		{"₦1,000.00", "NGN", 1000000000},
+		{"E£100.00", "EGP", 100000000},
+		{"د.إ.‏ 20.00", "AED", 20000000},
+		{"KWD 1.500", "KWD", 1500000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.display, func(t *testing.T) {
+			money, err := ParseMoney(tt.display)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if money.Currency != tt.currency {
+				t.Errorf("Expected currency %s, got %s", tt.currency, money.Currency)
+			}
+			if money.Micros != tt.micros {
+				t.Errorf("Expected %d micros, got %d", tt.micros, money.Micros)
+			}
+			if money.Display != tt.display {
+				t.Errorf("Expected display %q, got %q", tt.display, money.Display)
+			}
+		})
+	}
+}
+
+func TestParseMoney_Errors(t *testing.T) {
+	t.Run("Ambiguous currency keeps the amount", func(t *testing.T) {
+		money, err := ParseMoney("50,00 kr")
+		if err == nil {
+			t.Fatal("Expected error")
+		}
+		if money.Currency != "" || money.Micros != 50000000 || money.Display != "50,00 kr" {
+			t.Errorf("Unexpected money %+v", money)
+		}
+	})
+
+	for _, display := range []string{"", "free", "¥"} {
+		t.Run(display, func(t *testing.T) {
+			money, err := ParseMoney(display)
+			if err == nil {
+				t.Errorf("Expected error for %q, got %+v", display, money)
+			}
+		})
+	}
+}
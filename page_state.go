@@ -0,0 +1,133 @@
+package youtubechat
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// PageState describes why a live page cannot be observed
+type PageState int
+
+const (
+	PageStateNotLive PageState = iota + 1
+	PageStateFinished
+	PageStateMembersOnly
+	PageStateChatDisabled
+	PageStateAgeRestricted
+	PageStatePrivate
+	PageStateUnavailable
+	PageStateRegionBlocked
+)
+
+func (s PageState) String() string {
+	switch s {
+	case PageStateNotLive:
+		return "not live"
+	case PageStateFinished:
+		return "finished"
+	case PageStateMembersOnly:
+		return "members only"
+	case PageStateChatDisabled:
+		return "chat disabled"
+	case PageStateAgeRestricted:
+		return "age restricted"
+	case PageStatePrivate:
+		return "private"
+	case PageStateUnavailable:
+		return "unavailable"
+	case PageStateRegionBlocked:
+		return "region blocked"
+	}
+	return fmt.Sprintf("PageState(%d)", int(s))
+}
+
+// PageStateError is returned by GetOptionsFromLivePage when the page shows
+// a stream that cannot be observed. Use errors.As to inspect the State.
+type PageStateError struct {
+	State  PageState
+	LiveID string // empty when the page does not expose a video
+	Reason string // YouTube's own message, if any
+}
+
+func (e *PageStateError) Error() string {
+	switch e.State {
+	case PageStateNotLive:
+		return "Live Stream was not found"
+	case PageStateFinished:
+		return fmt.Sprintf("%s is finished live", e.LiveID)
+	}
+
+	msg := fmt.Sprintf("%s is %s", e.LiveID, e.State)
+	if e.LiveID == "" {
+		msg = fmt.Sprintf("Live Stream is %s", e.State)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+var (
+	regexPlayabilityStatus = regexp.MustCompile(`['"]playabilityStatus['"]:\s*\{`)
+	regexPlayabilityField  = regexp.MustCompile(`^\{\s*['"]status['"]:\s*['"](\w+)['"]`)
+	regexPlayabilityReason = regexp.MustCompile(`^\{[^{}]*?['"]reason['"]:\s*` + jsString)
+	regexMembersOnlyOffer  = regexp.MustCompile(`['"]offerId['"]:\s*['"]sponsors_only_video['"]`)
+	regexAgeGate           = regexp.MustCompile(`['"]desktopLegacyAgeGateReason['"]`)
+	regexRegionBlocked     = regexp.MustCompile(`(?i)available in your country|disponible en tu país|no seu país|dans votre pays|in deinem Land|お住まいの国`)
+	regexConversationBar   = regexp.MustCompile(`['"]conversationBar['"]:\s*\{`)
+	regexLiveChatRenderer  = regexp.MustCompile(`^\{\s*['"]liveChatRenderer['"]:\s*\{`)
+)
+
+// detectPlayabilityState maps the player's playabilityStatus to a PageState.
+// Only the playabilityStatus object is looked at, never the rest of the page.
+// It returns 0 when the video is playable.
+func detectPlayabilityState(data string) (PageState, string) {
+	playability := findObject(data, regexPlayabilityStatus)
+	m := regexPlayabilityField.FindStringSubmatch(playability)
+	if len(m) < 2 {
+		return 0, ""
+	}
+	status := m[1]
+
+	reason := ""
+	if rm := regexPlayabilityReason.FindStringSubmatch(playability); len(rm) > 2 {
+		reason = unquoteJSString(rm[1], rm[2])
+	}
+
+	switch status {
+	case "OK", "LIVE_STREAM_OFFLINE":
+		return 0, ""
+	}
+
+	if regexMembersOnlyOffer.MatchString(playability) {
+		return PageStateMembersOnly, reason
+	}
+
+	switch status {
+	case "AGE_CHECK_REQUIRED", "AGE_VERIFICATION_REQUIRED", "CONTENT_CHECK_REQUIRED":
+		return PageStateAgeRestricted, reason
+	case "LOGIN_REQUIRED":
+		if regexAgeGate.MatchString(playability) {
+			return PageStateAgeRestricted, reason
+		}
+		return PageStatePrivate, reason
+	case "UNPLAYABLE":
+		// YouTube only says why in the (localized) reason and error screen
+		if regexRegionBlocked.MatchString(playability) {
+			return PageStateRegionBlocked, reason
+		}
+		return PageStateUnavailable, reason
+	}
+
+	return PageStateUnavailable, reason
+}
+
+// detectChatState reports PageStateChatDisabled when the conversation bar of a
+// live page does not hold the chat, e.g. a conversationBarRenderer saying why.
+// It returns 0 when the chat is available.
+func detectChatState(data string) PageState {
+	if !regexLiveChatRenderer.MatchString(findObject(data, regexConversationBar)) {
+		return PageStateChatDisabled
+	}
+	return 0
+}
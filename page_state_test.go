@@ -0,0 +1,125 @@
+package youtubechat
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestPageState(t *testing.T) {
+	const canonical = `<link rel="canonical" href="https://www.youtube.com/watch?v=liveId">`
+
+	tests := []struct {
+		name     string
+		data     string
+		expected PageState
+		reason   string
+	}{
+		{
+			name:     "Members only",
+			data:     canonical + `"playabilityStatus":{"status":"UNPLAYABLE","reason":"Join this channel to get access to members-only content like this video, and other exclusive perks.","errorScreen":{"playerLegacyDesktopYpcOfferRenderer":{"offerId":"sponsors_only_video"}}}`,
+			expected: PageStateMembersOnly,
+			reason:   "Join this channel to get access to members-only content like this video, and other exclusive perks.",
+		},
+		{
+			name:     "Age restricted",
+			data:     canonical + `"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"Sign in to confirm your age","desktopLegacyAgeGateReason":1}`,
+			expected: PageStateAgeRestricted,
+			reason:   "Sign in to confirm your age",
+		},
+		{
+			name:     "Age verification",
+			data:     canonical + `"playabilityStatus":{"status":"AGE_VERIFICATION_REQUIRED"}`,
+			expected: PageStateAgeRestricted,
+		},
+		{
+			name:     "Private",
+			data:     `"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"Private video"}`,
+			expected: PageStatePrivate,
+			reason:   "Private video",
+		},
+		{
+			name:     "Removed",
+			data:     `"playabilityStatus":{"status":"ERROR","reason":"Video unavailable"}`,
+			expected: PageStateUnavailable,
+			reason:   "Video unavailable",
+		},
+		{
+			name:     "Region blocked",
+			data:     canonical + `"playabilityStatus":{"status":"UNPLAYABLE","reason":"Video unavailable","errorScreen":{"playerErrorMessageRenderer":{"subreason":{"simpleText":"The uploader has not made this video available in your country"}}}}`,
+			expected: PageStateRegionBlocked,
+			reason:   "Video unavailable",
+		},
+		{
+			name:     "Region blocked in Spanish",
+			data:     canonical + `"playabilityStatus":{"status":"UNPLAYABLE","reason":"Video no disponible","errorScreen":{"playerErrorMessageRenderer":{"subreason":{"runs":[{"text":"El usuario que ha subido este vídeo no ha permitido que esté disponible en tu país"}]}}}}`,
+			expected: PageStateRegionBlocked,
+			reason:   "Video no disponible",
+		},
+		{
+			name:     "Unplayable with country in description",
+			data:     canonical + `"playabilityStatus":{"status":"UNPLAYABLE","reason":"Video unavailable"},"description":{"simpleText":"Not available in your country? Use the replay"}`,
+			expected: PageStateUnavailable,
+			reason:   "Video unavailable",
+		},
+		{
+			name:     "Chat disabled",
+			data:     canonical + `"playabilityStatus":{"status":"OK"},"conversationBar":{"conversationBarRenderer":{"availabilityMessage":{}}}`,
+			expected: PageStateChatDisabled,
+		},
+		{
+			name:     "No chat",
+			data:     canonical + `"playabilityStatus":{"status":"OK"}`,
+			expected: PageStateChatDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetOptionsFromLivePage(tt.data)
+
+			var stateErr *PageStateError
+			if !errors.As(err, &stateErr) {
+				t.Fatalf("Expected PageStateError, got %v", err)
+			}
+			if stateErr.State != tt.expected {
+				t.Errorf("Expected state %s, got %s", tt.expected, stateErr.State)
+			}
+			if stateErr.Reason != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, stateErr.Reason)
+			}
+		})
+	}
+
+	t.Run("Chat available", func(t *testing.T) {
+		data := `"conversationBar":{"liveChatRenderer":{"continuations":[]}},"engagementPanels":[{"conversationBarRenderer":{}}]`
+		if state := detectChatState(data); state != 0 {
+			t.Errorf("Expected chat available, got %s", state)
+		}
+	})
+
+	files := map[string]PageState{
+		"replay_page.html":  PageStateFinished,
+		"no_live_page.html": PageStateNotLive,
+	}
+
+	for filename, expected := range files {
+		t.Run(filename, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = GetOptionsFromLivePage(string(data))
+
+			var stateErr *PageStateError
+			if !errors.As(err, &stateErr) {
+				t.Fatalf("Expected PageStateError, got %v", err)
+			}
+			if stateErr.State != expected {
+				t.Errorf("Expected state %s, got %s", expected, stateErr.State)
+			}
+		})
+	}
+}
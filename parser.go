@@ -1,9 +1,13 @@
 package youtubechat
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,12 +15,37 @@ import (
 	"github.com/DiegPS/youtube-chat/types"
 )
 
+// jsString matches a double or single quoted string literal, capturing its contents
+const jsString = `(?:"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)')`
+
 var (
 	regexCanonical    = regexp.MustCompile(`<link rel="canonical" href="https:\/\/www.youtube.com\/watch\?v=(.+?)">`)
 	regexIsReplay     = regexp.MustCompile(`['"]isReplay['"]:\s*(true)`)
 	regexAPIKey       = regexp.MustCompile(`['"]INNERTUBE_API_KEY['"]:\s*['"](.+?)['"]`)
 	regexClientVer    = regexp.MustCompile(`['"]clientVersion['"]:\s*['"]([\d.]+?)['"]`)
 	regexContinuation = regexp.MustCompile(`['"]continuation['"]:\s*['"](.+?)['"]`)
+
+	regexMetaChannelID  = regexp.MustCompile(`<meta itemprop="channelId" content="(UC[A-Za-z0-9_-]{22})">`)
+	regexExternalID     = regexp.MustCompile(`['"]externalId['"]:\s*['"](UC[A-Za-z0-9_-]{22})['"]`)
+	regexVideoChannelID = regexp.MustCompile(`['"]videoDetails['"]:\s*\{[^}]*?['"]channelId['"]:\s*['"](UC[A-Za-z0-9_-]{22})['"]`)
+	regexChannelTitle   = regexp.MustCompile(`['"]channelMetadataRenderer['"]:\s*\{\s*['"]title['"]:\s*` + jsString)
+	regexVideoAuthor    = regexp.MustCompile(`['"]author['"]:\s*` + jsString)
+	regexOwnerHandle    = regexp.MustCompile(`['"](?:vanityChannelUrl|ownerProfileUrl)['"]:\s*['"]https?://www\.youtube\.com/(@[^'"/?]+)['"]`)
+
+	regexBadgeTooltip  = regexp.MustCompile(`^(.*?)\s*[(（]([^)）]*)[)）]\s*$`)
+	regexBadgeDuration = regexp.MustCompile(`(\d+)\s*([^\d\s,、]*)`)
+	regexBadgeYears    = regexp.MustCompile(`(?i)^(year|yr|año|ano|an|jahr|년|年)`)
+	regexModeOn        = regexp.MustCompile(`(?i)\b(on|enabled|activado|ativado)\b`)
+	regexModeOff       = regexp.MustCompile(`(?i)\b(off|disabled|desactivado|desativado)\b`)
+	regexSlowModeDelay = regexp.MustCompile(`(?i)(\d+)\s*(sec|seg|sek|min|秒|分)`)
+	regexVoteCount     = regexp.MustCompile(`(\d[\d,.]*)\s*([KkMm]?)`)
+
+	regexInitialData = regexp.MustCompile(`(?:window\[['"]ytInitialData['"]\]|var ytInitialData)\s*=\s*`)
+
+	regexChatHeader         = regexp.MustCompile(`['"]liveChatHeaderRenderer['"]:\s*\{`)
+	regexViewSelector       = regexp.MustCompile(`['"]sortFilterSubMenuRenderer['"]:\s*\{`)
+	regexStartTimestamp     = regexp.MustCompile(`['"]liveBroadcastDetails['"]:\s*\{[^}]*?['"]startTimestamp['"]:\s*['"]([^'"]+)['"]`)
+	regexReloadContinuation = regexp.MustCompile(`['"]reloadContinuationData['"]:\s*\{\s*['"]continuation['"]:\s*['"](.+?)['"]`)
 )
 
 func GetOptionsFromLivePage(data string) (types.FetchOptions, error) {
@@ -26,13 +55,25 @@ func GetOptionsFromLivePage(data string) (types.FetchOptions, error) {
 	liveIDMatch := regexCanonical.FindStringSubmatch(data)
 	if len(liveIDMatch) > 1 {
 		opts.LiveID = liveIDMatch[1]
-	} else {
-		return opts, errors.New("Live Stream was not found")
+	}
+
+	// Members-only, private, removed...
+	if state, reason := detectPlayabilityState(data); state != 0 {
+		return opts, &PageStateError{State: state, LiveID: opts.LiveID, Reason: reason}
+	}
+
+	if opts.LiveID == "" {
+		return opts, &PageStateError{State: PageStateNotLive}
 	}
 
 	// Replay
 	if regexIsReplay.MatchString(data) {
-		return opts, fmt.Errorf("%s is finished live", opts.LiveID)
+		return opts, &PageStateError{State: PageStateFinished, LiveID: opts.LiveID}
+	}
+
+	// Chat disabled
+	if state := detectChatState(data); state != 0 {
+		return opts, &PageStateError{State: state, LiveID: opts.LiveID}
 	}
 
 	// API Key
@@ -59,32 +100,737 @@ func GetOptionsFromLivePage(data string) (types.FetchOptions, error) {
 		return opts, errors.New("Continuation was not found")
 	}
 
+	opts.TopChatContinuation, opts.LiveChatContinuation = parseViewSelectorContinuations(data)
+	opts.Channel = parseChannel(data)
+	opts.StartTime = parseStartTime(data)
+
+	return opts, nil
+}
+
+// parseStartTime reads liveBroadcastDetails.startTimestamp from the player
+// microformat, zero when the stream has not started
+func parseStartTime(data string) time.Time {
+	m := regexStartTimestamp.FindStringSubmatch(data)
+	if len(m) < 2 {
+		return time.Time{}
+	}
+	start, err := time.Parse(time.RFC3339, m[1])
+	if err != nil {
+		return time.Time{}
+	}
+	return start
+}
+
+// GetOptionsFromNextResponse extracts chat options from an innertube next response.
+// ApiKey and ClientVersion are left for the caller to fill.
+func GetOptionsFromNextResponse(data types.NextResponse) (types.FetchOptions, error) {
+	var opts types.FetchOptions
+
+	opts.LiveID = data.CurrentVideoEndpoint.WatchEndpoint.VideoId
+	results := data.Contents.TwoColumnWatchNextResults
+
+	for _, content := range results.Results.Results.Contents {
+		if content.VideoSecondaryInfoRenderer == nil {
+			continue
+		}
+		owner := content.VideoSecondaryInfoRenderer.Owner.VideoOwnerRenderer
+		opts.Channel.ID = owner.NavigationEndpoint.BrowseEndpoint.BrowseId
+		if base := owner.NavigationEndpoint.BrowseEndpoint.CanonicalBaseUrl; strings.HasPrefix(base, "/@") {
+			opts.Channel.Handle = base[1:]
+		}
+		for _, run := range owner.Title.Runs {
+			opts.Channel.Title += run.Text
+		}
+		break
+	}
+
+	bar := results.ConversationBar
+	if opts.LiveID == "" || bar == nil {
+		return opts, &PageStateError{State: PageStateNotLive}
+	}
+	if bar.LiveChatRenderer == nil {
+		return opts, &PageStateError{State: PageStateChatDisabled, LiveID: opts.LiveID}
+	}
+
+	chat := bar.LiveChatRenderer
+	if chat.IsReplay {
+		return opts, &PageStateError{State: PageStateFinished, LiveID: opts.LiveID}
+	}
+
+	opts.Continuation = parseContinuation(chat.Continuations)
+	if opts.Continuation == "" {
+		return opts, errors.New("Continuation was not found")
+	}
+
+	items := chat.Header.LiveChatHeaderRenderer.ViewSelector.SortFilterSubMenuRenderer.SubMenuItems
+	if len(items) >= 2 {
+		opts.TopChatContinuation = parseContinuation([]types.Continuation{items[0].Continuation})
+		opts.LiveChatContinuation = parseContinuation([]types.Continuation{items[1].Continuation})
+	}
+
+	return opts, nil
+}
+
+// GetChannelFromPage extracts the owning channel from a watch, live or channel page
+func GetChannelFromPage(data string) (types.Channel, error) {
+	channel := parseChannel(data)
+	if channel.ID == "" {
+		return channel, errors.New("Channel ID was not found")
+	}
+	return channel, nil
+}
+
+func parseChannel(data string) types.Channel {
+	var channel types.Channel
+
+	for _, re := range []*regexp.Regexp{regexMetaChannelID, regexExternalID, regexVideoChannelID} {
+		if m := re.FindStringSubmatch(data); len(m) > 1 {
+			channel.ID = m[1]
+			break
+		}
+	}
+
+	if m := regexOwnerHandle.FindStringSubmatch(data); len(m) > 1 {
+		channel.Handle = m[1]
+	}
+
+	for _, re := range []*regexp.Regexp{regexChannelTitle, regexVideoAuthor} {
+		if m := re.FindStringSubmatch(data); len(m) > 2 {
+			channel.Title = unquoteJSString(m[1], m[2])
+			break
+		}
+	}
+
+	return channel
+}
+
+// unquoteJSString decodes the groups captured by jsString, falling back to the raw text
+// when the literal uses escapes JSON does not understand.
+func unquoteJSString(double, single string) string {
+	raw := double
+	if raw == "" {
+		raw = strings.ReplaceAll(single, `\'`, "'")
+		raw = strings.ReplaceAll(raw, `"`, `\"`)
+	}
+
+	var s string
+	if err := json.Unmarshal([]byte(`"`+raw+`"`), &s); err != nil {
+		return raw
+	}
+	return s
+}
+
+// findObject returns the object literal whose opening brace ends the first
+// match of re, empty when there is none
+func findObject(data string, re *regexp.Regexp) string {
+	loc := re.FindStringIndex(data)
+	if loc == nil {
+		return ""
+	}
+	return objectAt(data, loc[1]-1)
+}
+
+// objectAt returns the JSON or JavaScript object literal opening at
+// data[start], skipping braces inside quoted strings. It is empty when the
+// object is not closed.
+func objectAt(data string, start int) string {
+	depth := 0
+	var quote byte
+	for i := start; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return data[start : i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// parseViewSelectorContinuations extracts the continuations of the chat header's
+// view selector. YouTube always lists "Top chat" first and "Live chat" second;
+// the titles are localized so they are matched by position. Other sort menus
+// on the page, e.g. the comments', are not looked at.
+func parseViewSelectorContinuations(data string) (string, string) {
+	header := findObject(findObject(data, regexConversationBar), regexChatHeader)
+	selector := findObject(header, regexViewSelector)
+
+	matches := regexReloadContinuation.FindAllStringSubmatch(selector, 2)
+	if len(matches) < 2 {
+		return "", ""
+	}
+
+	return matches[0][1], matches[1][1]
+}
+
+// SelectChatMode returns options whose continuation points at the requested chat view
+func SelectChatMode(opts types.FetchOptions, mode types.ChatMode) (types.FetchOptions, error) {
+	switch mode {
+	case types.ChatModeTop:
+		if opts.TopChatContinuation == "" {
+			return opts, errors.New("Top chat continuation was not found")
+		}
+		opts.Continuation = opts.TopChatContinuation
+	case types.ChatModeLive:
+		if opts.LiveChatContinuation == "" {
+			return opts, errors.New("Live chat continuation was not found")
+		}
+		opts.Continuation = opts.LiveChatContinuation
+	}
 	return opts, nil
 }
 
 func ParseChatData(data types.GetLiveChatResponse) ([]types.ChatItem, string) {
-	var chatItems []types.ChatItem
+	actions, continuation := ParseChatActions(data)
+	return actions.Items, continuation
+}
+
+// ParseChatActions is ParseChatData including events other than new chat items
+func ParseChatActions(data types.GetLiveChatResponse) (types.ChatActions, string) {
+	chat := data.ContinuationContents.LiveChatContinuation
+	return parseActions(chat.Actions, parseOptions{}), parseContinuation(chat.Continuations)
+}
+
+type parseOptions struct {
+	keepRaw bool
+}
+
+// ParseOption configures ParseChatResponse and ParseLiveChatPage
+type ParseOption func(*parseOptions)
+
+// WithRawJSON keeps the JSON of each action in the Raw field of the events
+// decoded from it
+func WithRawJSON() ParseOption {
+	return func(o *parseOptions) {
+		o.keepRaw = true
+	}
+}
+
+// ParseChatResponse is ParseChatActions for a get_live_chat response body
+func ParseChatResponse(body []byte, opts ...ParseOption) (types.ChatActions, string, error) {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var data types.GetLiveChatResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return types.ChatActions{}, "", err
+	}
+	chat := &data.ContinuationContents.LiveChatContinuation
+
+	if o.keepRaw {
+		var raw struct {
+			ContinuationContents struct {
+				LiveChatContinuation struct {
+					Actions []json.RawMessage `json:"actions"`
+				} `json:"liveChatContinuation"`
+			} `json:"continuationContents"`
+		}
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return types.ChatActions{}, "", err
+		}
+		setRaw(chat.Actions, raw.ContinuationContents.LiveChatContinuation.Actions)
+	}
+
+	return parseActions(chat.Actions, o), parseContinuation(chat.Continuations), nil
+}
+
+// setRaw pairs decoded actions with the JSON of the same array
+func setRaw(actions []types.Action, raw []json.RawMessage) {
+	if len(actions) != len(raw) {
+		return
+	}
+	for i := range actions {
+		actions[i].Raw = raw[i]
+	}
+}
+
+// ParseLiveChatPage extracts the initial chat backlog and the continuation following it
+// from the live_chat popout page. Returned chat items, including those inside
+// replacements, tickers and banners, are flagged IsBacklog; other events carry
+// no flag.
+func ParseLiveChatPage(data string, opts ...ParseOption) (types.ChatActions, string, error) {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	loc := regexInitialData.FindStringIndex(data)
+	if loc == nil {
+		return types.ChatActions{}, "", errors.New("Initial data was not found")
+	}
+
+	var pageData types.LiveChatPageData
+	// The decoder stops after the object, ignoring the rest of the script
+	if err := json.NewDecoder(strings.NewReader(data[loc[1]:])).Decode(&pageData); err != nil {
+		return types.ChatActions{}, "", err
+	}
+
+	chat := pageData.Contents.LiveChatRenderer
+	if chat == nil {
+		return types.ChatActions{}, "", errors.New("Live chat was not found")
+	}
+
+	if o.keepRaw {
+		var raw struct {
+			Contents struct {
+				LiveChatRenderer struct {
+					Actions []json.RawMessage `json:"actions"`
+				} `json:"liveChatRenderer"`
+			} `json:"contents"`
+		}
+		if err := json.NewDecoder(strings.NewReader(data[loc[1]:])).Decode(&raw); err != nil {
+			return types.ChatActions{}, "", err
+		}
+		setRaw(chat.Actions, raw.Contents.LiveChatRenderer.Actions)
+	}
 
-	if data.ContinuationContents.LiveChatContinuation.Actions != nil {
-		for _, action := range data.ContinuationContents.LiveChatContinuation.Actions {
-			item := parseActionToChatItem(action)
-			if item != nil {
-				chatItems = append(chatItems, *item)
+	actions := parseActions(chat.Actions, o)
+	for i := range actions.Items {
+		actions.Items[i].IsBacklog = true
+	}
+	for i := range actions.System {
+		actions.System[i].IsBacklog = true
+	}
+	for i := range actions.Replacements {
+		actions.Replacements[i].Item.IsBacklog = true
+	}
+	for _, ticker := range actions.Tickers {
+		if ticker.LinkedItem != nil {
+			ticker.LinkedItem.IsBacklog = true
+		}
+	}
+	for _, banner := range actions.Banners {
+		if banner.Banner.Item != nil {
+			banner.Banner.Item.IsBacklog = true
+		}
+	}
+
+	return actions, parseContinuation(chat.Continuations), nil
+}
+
+func parseActions(actions []types.Action, o parseOptions) types.ChatActions {
+	var result types.ChatActions
+	for _, action := range actions {
+		var raw json.RawMessage
+		if o.keepRaw {
+			raw = action.Raw
+		}
+		if replay := action.ReplayChatItemAction; replay != nil {
+			for _, event := range parseReplayActions(replay, o).Events() {
+				result.Add(event)
 			}
+		} else if item := parseActionToChatItem(action); item != nil {
+			item.Raw = raw
+			result.Add(*item)
+		} else if deletion := parseDeletion(action); deletion != nil {
+			deletion.Raw = raw
+			result.Add(*deletion)
+		} else if replacement := parseReplacement(action); replacement != nil {
+			replacement.Raw = raw
+			result.Add(*replacement)
+		} else if ticker := parseTicker(action); ticker != nil {
+			ticker.Raw = raw
+			result.Add(*ticker)
+		} else if poll := parsePollEvent(action); poll != nil {
+			poll.Raw = raw
+			result.Add(*poll)
+		} else if banner := parseBannerEvent(action); banner != nil {
+			banner.Raw = raw
+			result.Add(*banner)
+			if redirect := parseRedirect(action); redirect != nil {
+				redirect.Raw = raw
+				result.Add(*redirect)
+			}
+		} else {
+			result.Add(parseUnhandled(action))
+		}
+	}
+	return result
+}
+
+// parseReplayActions sets the video offset on the items a replay action wraps
+func parseReplayActions(replay *types.ReplayChatItemAction, o parseOptions) types.ChatActions {
+	actions := parseActions(replay.Actions, o)
+	if msec, err := strconv.ParseInt(replay.VideoOffsetTimeMsec, 10, 64); err == nil {
+		for i := range actions.Items {
+			actions.Items[i].Offset = time.Duration(msec) * time.Millisecond
+			actions.Items[i].HasOffset = true
 		}
 	}
+	return actions
+}
 
-	continuation := ""
-	if len(data.ContinuationContents.LiveChatContinuation.Continuations) > 0 {
-		contData := data.ContinuationContents.LiveChatContinuation.Continuations[0]
-		if contData.InvalidationContinuationData != nil {
-			continuation = contData.InvalidationContinuationData.Continuation
-		} else if contData.TimedContinuationData != nil {
-			continuation = contData.TimedContinuationData.Continuation
+// parseUnhandled names an unrecognized action and the renderer of the item or
+// banner it adds or replaces, if any
+func parseUnhandled(action types.Action) types.UnhandledAction {
+	raw := action.Raw
+	if raw == nil {
+		// A modeled action the parser still could not use, only its modeled
+		// fields are left
+		raw, _ = json.Marshal(action)
+	}
+	unhandled := types.UnhandledAction{Raw: raw}
+
+	key, value := firstKey(raw, "clickTrackingParams")
+	if key == "" {
+		return unhandled
+	}
+	unhandled.Key = key
+	unhandled.Renderer = value
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(value, &fields) != nil {
+		return unhandled
+	}
+	for _, field := range []string{"item", "replacementItem"} {
+		if rendererKey, renderer := firstKey(fields[field]); rendererKey != "" {
+			unhandled.RendererKey = rendererKey
+			unhandled.Renderer = renderer
+			return unhandled
 		}
 	}
 
-	return chatItems, continuation
+	var banner struct {
+		LiveChatBannerRenderer struct {
+			Contents json.RawMessage `json:"contents"`
+		} `json:"liveChatBannerRenderer"`
+	}
+	if json.Unmarshal(fields["bannerRenderer"], &banner) == nil {
+		if rendererKey, renderer := firstKey(banner.LiveChatBannerRenderer.Contents); rendererKey != "" {
+			unhandled.RendererKey = rendererKey
+			unhandled.Renderer = renderer
+		}
+	}
+	return unhandled
+}
+
+// firstKey returns the first key of a JSON object in sorted order, skipping ignored keys
+func firstKey(raw json.RawMessage, ignored ...string) (string, json.RawMessage) {
+	var fields map[string]json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &fields) != nil {
+		return "", nil
+	}
+	for _, key := range ignored {
+		delete(fields, key)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+	sort.Strings(keys)
+	return keys[0], fields[keys[0]]
+}
+
+func parseReplacement(data types.Action) *types.Replacement {
+	if data.ReplaceChatItemAction == nil {
+		return nil
+	}
+	r := data.ReplaceChatItemAction
+
+	item := parseActionItem(r.ReplacementItem)
+	if item == nil {
+		return nil
+	}
+
+	return &types.Replacement{
+		TargetItemID: r.TargetItemId,
+		Item:         *item,
+	}
+}
+
+func parseTicker(data types.Action) *types.Ticker {
+	if data.AddLiveChatTickerItemAction == nil {
+		return nil
+	}
+	item := data.AddLiveChatTickerItemAction.Item
+
+	var base *types.TickerRendererBase
+	var ticker types.Ticker
+	var photo []types.Thumbnail
+
+	if r := item.LiveChatTickerPaidMessageItemRenderer; r != nil {
+		base = &r.TickerRendererBase
+		photo = r.AuthorPhoto.Thumbnails
+		ticker.Kind = types.TickerPaidMessage
+		ticker.Amount = r.Amount.SimpleText
+		ticker.AmountTextColor = convertColorToHex6(r.AmountTextColor)
+	} else if r := item.LiveChatTickerPaidStickerItemRenderer; r != nil {
+		base = &r.TickerRendererBase
+		photo = r.AuthorPhoto.Thumbnails
+		ticker.Kind = types.TickerPaidSticker
+		if len(r.TickerThumbnails) > 0 {
+			sticker := r.TickerThumbnails[0]
+			ticker.Sticker = parseThumbnailToImageItem(sticker.Thumbnails, sticker.Accessibility.AccessibilityData.Label)
+		}
+	} else if r := item.LiveChatTickerSponsorItemRenderer; r != nil {
+		base = &r.TickerRendererBase
+		photo = r.SponsorPhoto.Thumbnails
+		ticker.Kind = types.TickerMembership
+		ticker.Amount = r.DetailText.SimpleText
+		for _, run := range r.DetailText.Runs {
+			ticker.Amount += run.Text
+		}
+		ticker.AmountTextColor = convertColorToHex6(r.DetailTextColor)
+		if r.ShowItemEndpoint.ShowLiveChatItemEndpoint.Renderer.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer != nil {
+			ticker.Kind = types.TickerGift
+		}
+	} else {
+		return nil
+	}
+
+	ticker.ID = base.ID
+	ticker.StartBackgroundColor = convertColorToHex6(base.StartBackgroundColor)
+	ticker.EndBackgroundColor = convertColorToHex6(base.EndBackgroundColor)
+	ticker.Duration = time.Duration(base.DurationSec) * time.Second
+	ticker.FullDuration = time.Duration(base.FullDurationSec) * time.Second
+	ticker.Author = types.Author{
+		ChannelID: base.AuthorExternalChannelId,
+		Thumbnail: parseThumbnailToImageItem(photo, ""),
+	}
+
+	if linked := parseActionItem(base.ShowItemEndpoint.ShowLiveChatItemEndpoint.Renderer); linked != nil {
+		ticker.LinkedItemID = linked.ID
+		ticker.LinkedItem = linked
+		ticker.Author.Name = linked.Author.Name
+		ticker.Author.Thumbnail.Alt = linked.Author.Name
+	}
+
+	return &ticker
+}
+
+func parseBannerEvent(data types.Action) *types.BannerEvent {
+	if c := data.AddBannerToLiveChatCommand; c != nil && c.BannerRenderer.LiveChatBannerRenderer != nil {
+		banner := parseBanner(c.BannerRenderer.LiveChatBannerRenderer)
+		if banner.Kind == types.BannerUnknown {
+			// Left to parseUnhandled
+			return nil
+		}
+		return &types.BannerEvent{
+			Kind:   types.BannerAdded,
+			Banner: banner,
+		}
+	} else if c := data.RemoveBannerForLiveChatCommand; c != nil {
+		return &types.BannerEvent{
+			Kind:   types.BannerRemoved,
+			Banner: types.Banner{ID: c.TargetActionId},
+		}
+	}
+	return nil
+}
+
+func parseBanner(r *types.LiveChatBannerRenderer) types.Banner {
+	banner := types.Banner{ID: r.ActionId}
+	if r.Header != nil {
+		banner.Header = parseMessages(r.Header.LiveChatBannerHeaderRenderer.Text.Runs)
+	}
+
+	contents := r.Contents
+	if item := parseActionItem(contents.ActionItem); item != nil {
+		banner.Kind = types.BannerPinnedMessage
+		banner.Item = item
+	} else if c := contents.LiveChatBannerChatSummaryRenderer; c != nil {
+		banner.Kind = types.BannerChatSummary
+		banner.Text = parseMessages(c.ChatSummary.Runs)
+	} else if c := contents.LiveChatBannerRedirectRenderer; c != nil {
+		banner.Kind = types.BannerRedirect
+		banner.Text = parseMessages(c.BannerMessage.Runs)
+		banner.Thumbnail = parseThumbnailToImageItem(c.AuthorPhoto.Thumbnails, "")
+	}
+
+	return banner
+}
+
+func parseRedirect(data types.Action) *types.Redirect {
+	c := data.AddBannerToLiveChatCommand
+	if c == nil || c.BannerRenderer.LiveChatBannerRenderer == nil {
+		return nil
+	}
+	banner := c.BannerRenderer.LiveChatBannerRenderer
+	r := banner.Contents.LiveChatBannerRedirectRenderer
+	if r == nil {
+		return nil
+	}
+
+	// "@name and their viewers just joined" or "... watch something from @name"
+	var other types.Channel
+	for _, run := range r.BannerMessage.Runs {
+		e := run.NavigationEndpoint
+		linked := e != nil && e.BrowseEndpoint != nil
+		if !linked && !strings.HasPrefix(strings.TrimSpace(run.Text), "@") {
+			continue
+		}
+		if linked {
+			other.ID = e.BrowseEndpoint.BrowseId
+		}
+		if name := strings.TrimSpace(run.Text); strings.HasPrefix(name, "@") {
+			other.Handle = name
+		} else {
+			other.Title = name
+		}
+		break
+	}
+	if other.Handle == "" && other.ID == "" && r.InlineActionButton == nil && len(r.BannerMessage.Runs) > 1 {
+		// Older raid banners start with the raider's display name
+		other.Title = strings.TrimSpace(r.BannerMessage.Runs[0].Text)
+	}
+
+	redirect := types.Redirect{
+		ID:      banner.ActionId,
+		Kind:    types.RedirectIncoming,
+		Source:  other,
+		Avatar:  parseThumbnailToImageItem(r.AuthorPhoto.Thumbnails, ""),
+		Message: parseMessages(r.BannerMessage.Runs),
+	}
+
+	if b := r.InlineActionButton; b != nil {
+		redirect.Kind = types.RedirectOutgoing
+		redirect.Source = types.Channel{}
+		redirect.Target = other
+		if w := b.ButtonRenderer.Command.WatchEndpoint; w != nil {
+			redirect.TargetVideoID = w.VideoId
+		}
+	}
+
+	return &redirect
+}
+
+func parsePollEvent(data types.Action) *types.PollEvent {
+	if a := data.ShowLiveChatActionPanelAction; a != nil && a.PanelToShow.LiveChatActionPanelRenderer != nil {
+		panel := a.PanelToShow.LiveChatActionPanelRenderer
+		poll := parsePoll(panel.Contents)
+		if poll == nil {
+			return nil
+		}
+		poll.PanelID = panel.ID
+		return &types.PollEvent{Kind: types.PollCreated, Poll: *poll}
+	} else if a := data.UpdateLiveChatPollAction; a != nil {
+		poll := parsePoll(a.PollToUpdate)
+		if poll == nil {
+			return nil
+		}
+		return &types.PollEvent{Kind: types.PollUpdated, Poll: *poll}
+	} else if a := data.CloseLiveChatActionPanelAction; a != nil {
+		// Only the panel is known here, LiveChat fills in the rest of the poll
+		return &types.PollEvent{
+			Kind: types.PollEnded,
+			Poll: types.Poll{PanelID: a.TargetPanelId, State: types.PollClosed},
+		}
+	}
+	return nil
+}
+
+func parsePoll(container types.PollContainer) *types.Poll {
+	r := container.PollRenderer
+	if r == nil {
+		r = container.LiveChatPollRenderer
+	}
+	if r == nil {
+		return nil
+	}
+	header := r.Header.PollHeaderRenderer
+
+	poll := types.Poll{
+		ID:       r.LiveChatPollId,
+		Question: parseMessages(header.PollQuestion.Runs),
+		State:    types.PollOpen,
+	}
+
+	// "authorName • just now • 12 votes"
+	metadata := header.MetadataText.Runs
+	if len(metadata) > 0 {
+		poll.Author.Name = metadata[0].Text
+		poll.TotalVotes = parseVoteCount(metadata[len(metadata)-1].Text)
+	}
+	poll.Author.Thumbnail = parseThumbnailToImageItem(header.Thumbnail.Thumbnails, poll.Author.Name)
+
+	for _, choice := range r.Choices {
+		poll.Choices = append(poll.Choices, types.PollChoice{
+			Text:           parseMessages(choice.Text.Runs),
+			VotePercentage: choice.VotePercentage.SimpleText,
+			VoteRatio:      choice.VoteRatio,
+			Selected:       choice.Selected,
+		})
+	}
+
+	return &poll
+}
+
+// parseVoteCount reads counts such as "12 votes", "1,234 votes", "1.2K votes"
+// or "1,5K votes"
+func parseVoteCount(text string) int {
+	m := regexVoteCount.FindStringSubmatch(text)
+	if len(m) < 3 {
+		return 0
+	}
+
+	multiplier := 0.0
+	switch strings.ToUpper(m[2]) {
+	case "K":
+		multiplier = 1000
+	case "M":
+		multiplier = 1000000
+	}
+	if multiplier != 0 {
+		// A comma followed by one or two digits is a decimal comma
+		number := m[1]
+		if i := strings.LastIndexByte(number, ','); i >= 0 && len(number)-i-1 <= 2 {
+			number = strings.ReplaceAll(number[:i], ".", "") + "." + number[i+1:]
+		}
+		f, _ := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+		return int(math.Round(f * multiplier))
+	}
+
+	n, _ := strconv.Atoi(strings.NewReplacer(",", "", ".", "").Replace(m[1]))
+	return n
+}
+
+func parseDeletion(data types.Action) *types.Deletion {
+	if r := data.MarkChatItemAsDeletedAction; r != nil {
+		return &types.Deletion{
+			TargetItemID: r.TargetItemId,
+			Message:      parseMessages(r.DeletedStateMessage.Runs),
+		}
+	} else if r := data.MarkChatItemsByAuthorAsDeletedAction; r != nil {
+		return &types.Deletion{
+			AuthorChannelID: r.ExternalChannelId,
+			Message:         parseMessages(r.DeletedStateMessage.Runs),
+		}
+	}
+	return nil
+}
+
+func parseContinuation(continuations []types.Continuation) string {
+	if len(continuations) == 0 {
+		return ""
+	}
+
+	contData := continuations[0]
+	if contData.InvalidationContinuationData != nil {
+		return contData.InvalidationContinuationData.Continuation
+	} else if contData.TimedContinuationData != nil {
+		return contData.TimedContinuationData.Continuation
+	} else if contData.ReloadContinuationData != nil {
+		return contData.ReloadContinuationData.Continuation
+	}
+	return ""
 }
 
 func parseThumbnailToImageItem(data []types.Thumbnail, alt string) *types.ImageItem {
@@ -115,50 +861,111 @@ func parseMessages(runs []types.MessageRun) []types.MessageItem {
 	var items []types.MessageItem
 	for _, run := range runs {
 		if run.Text != "" {
-			items = append(items, types.MessageItem{Text: run.Text})
-		} else if run.Emoji != nil {
-			// Emoji
-			var thumb *types.ImageItem
-			if len(run.Emoji.Image.Thumbnails) > 0 {
-				// shift() implies first element
-				firstThumb := run.Emoji.Image.Thumbnails[0]
-				thumb = &types.ImageItem{URL: firstThumb.URL}
-			} else {
-				thumb = &types.ImageItem{URL: "", Alt: ""}
+			item := types.MessageItem{
+				Text:          run.Text,
+				Bold:          run.Bold,
+				Italic:        run.Italics,
+				Strikethrough: run.Strikethrough,
 			}
-
-			isCustom := run.Emoji.IsCustomEmoji
-			shortcut := ""
-			if len(run.Emoji.Shortcuts) > 0 {
-				shortcut = run.Emoji.Shortcuts[0]
+			if run.NavigationEndpoint != nil {
+				parseRunLink(run, &item)
 			}
+			items = append(items, item)
+		} else if run.Emoji != nil {
+			items = append(items, types.MessageItem{Kind: types.RunEmoji, EmojiItem: parseEmoji(run.Emoji)})
+		}
+	}
+	return items
+}
 
-			// Update thumb alt
-			thumb.Alt = shortcut
+// parseRunLink resolves where a linked run points. Channel links written as
+// an @handle are mentions.
+func parseRunLink(run types.MessageRun, item *types.MessageItem) {
+	e := run.NavigationEndpoint
 
-			items = append(items, types.MessageItem{
-				EmojiItem: &types.EmojiItem{
-					ImageItem: *thumb,
-					EmojiText: func() string {
-						if isCustom {
-							return shortcut
-						} else {
-							return run.Emoji.EmojiId
-						}
-					}(),
-					IsCustomEmoji: isCustom,
-				},
-			})
+	if e.BrowseEndpoint != nil {
+		item.ChannelID = e.BrowseEndpoint.BrowseId
+		item.URL = YoutubeBaseURL + "/channel/" + e.BrowseEndpoint.BrowseId
+		if path := e.BrowseEndpoint.CanonicalBaseUrl; path != "" {
+			item.URL = YoutubeBaseURL + path
 		}
+	} else if e.WatchEndpoint != nil {
+		item.VideoID = e.WatchEndpoint.VideoId
+		item.URL = YoutubeBaseURL + "/watch?v=" + e.WatchEndpoint.VideoId
+	} else if e.UrlEndpoint != nil {
+		item.URL = unwrapRedirectURL(e.UrlEndpoint.URL)
+	} else if e.CommandMetadata != nil {
+		item.URL = e.CommandMetadata.WebCommandMetadata.URL
+	}
+
+	if strings.HasPrefix(item.URL, "/") {
+		item.URL = YoutubeBaseURL + item.URL
+	}
+	if item.URL == "" {
+		return
+	}
+
+	item.Kind = types.RunLink
+	if item.ChannelID != "" && strings.HasPrefix(strings.TrimSpace(item.Text), "@") {
+		item.Kind = types.RunMention
+	}
+}
+
+// unwrapRedirectURL returns the target of a youtube.com/redirect?q= link
+func unwrapRedirectURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Path != "/redirect" || !youtubeHosts[strings.ToLower(u.Hostname())] {
+		return raw
+	}
+	if q := u.Query().Get("q"); q != "" {
+		return q
+	}
+	return raw
+}
+
+func parseEmoji(emoji *types.MessageEmoji) *types.EmojiItem {
+	shortcut := ""
+	if len(emoji.Shortcuts) > 0 {
+		shortcut = emoji.Shortcuts[0]
+	}
+
+	// The first thumbnail, as before sizes were kept
+	thumb := types.ImageItem{Alt: shortcut}
+	if len(emoji.Image.Thumbnails) > 0 {
+		thumb.URL = emoji.Image.Thumbnails[0].URL
+	}
+
+	text := emoji.EmojiId
+	if emoji.IsCustomEmoji {
+		text = shortcut
+	}
+
+	return &types.EmojiItem{
+		ImageItem:        thumb,
+		EmojiText:        text,
+		IsCustomEmoji:    emoji.IsCustomEmoji,
+		Unicode:          resolveEmojiUnicode(emoji),
+		ID:               emoji.EmojiId,
+		Label:            emoji.Image.Accessibility.AccessibilityData.Label,
+		Thumbnails:       emoji.Image.Thumbnails,
+		Shortcuts:        emoji.Shortcuts,
+		SearchTerms:      emoji.SearchTerms,
+		VariantIDs:       emoji.VariantIds,
+		SupportsSkinTone: emoji.SupportsSkinTone,
 	}
-	return items
 }
 
 func parseActionToChatItem(data types.Action) *types.ChatItem {
 	if data.AddChatItemAction == nil {
 		return nil
 	}
-	item := data.AddChatItemAction.Item
+	return parseActionItem(data.AddChatItemAction.Item)
+}
+
+func parseActionItem(item types.ActionItem) *types.ChatItem {
+	if system := parseSystemItem(item); system != nil {
+		return system
+	}
 
 	var messageRenderer *types.MessageRendererBase
 	// Identifying renderer
@@ -170,6 +977,15 @@ func parseActionToChatItem(data types.Action) *types.ChatItem {
 		messageRenderer = &item.LiveChatPaidStickerRenderer.MessageRendererBase
 	} else if item.LiveChatMembershipItemRenderer != nil {
 		messageRenderer = &item.LiveChatMembershipItemRenderer.MessageRendererBase
+	} else if r := item.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer; r != nil {
+		// The author is only described in the header
+		base := r.Header.LiveChatSponsorshipsHeaderRenderer.MessageRendererBase
+		base.ID = r.ID
+		base.TimestampUsec = r.TimestampUsec
+		base.AuthorExternalChannelId = r.AuthorExternalChannelId
+		messageRenderer = &base
+	} else if item.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer != nil {
+		messageRenderer = &item.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer.MessageRendererBase
 	} else {
 		return nil
 	}
@@ -177,11 +993,22 @@ func parseActionToChatItem(data types.Action) *types.ChatItem {
 	var messageRuns []types.MessageRun
 	if item.LiveChatTextMessageRenderer != nil {
 		messageRuns = item.LiveChatTextMessageRenderer.Message.Runs
-	} else if item.LiveChatMembershipItemRenderer != nil {
-		messageRuns = item.LiveChatMembershipItemRenderer.HeaderSubtext.Runs
+	} else if r := item.LiveChatMembershipItemRenderer; r != nil {
+		if r.HeaderPrimaryText != nil {
+			// Milestone, the subtext is the tier and the message the member's comment
+			if r.Message != nil {
+				messageRuns = r.Message.Runs
+			}
+		} else {
+			messageRuns = r.HeaderSubtext.Runs
+		}
 	} else if item.LiveChatPaidMessageRenderer != nil {
 		// Paid message also has message
 		messageRuns = item.LiveChatPaidMessageRenderer.Message.Runs
+	} else if item.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer != nil {
+		messageRuns = item.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer.Header.LiveChatSponsorshipsHeaderRenderer.PrimaryText.Runs
+	} else if item.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer != nil {
+		messageRuns = item.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer.Message.Runs
 	}
 
 	authorNameText := ""
@@ -192,10 +1019,7 @@ func parseActionToChatItem(data types.Action) *types.ChatItem {
 	// Author thumbnails
 	authorThumb := parseThumbnailToImageItem(messageRenderer.AuthorPhoto.Thumbnails, authorNameText)
 
-	timestamp := time.Now()
-	if ts, err := strconv.ParseInt(messageRenderer.TimestampUsec, 10, 64); err == nil {
-		timestamp = time.Unix(ts/1000000, (ts%1000000)*1000)
-	}
+	timestamp, synthesized := parseTimestampUsec(messageRenderer.TimestampUsec)
 
 	idx := types.ChatItem{
 		ID: messageRenderer.ID,
@@ -210,50 +1034,317 @@ func parseActionToChatItem(data types.Action) *types.ChatItem {
 		IsOwner:      false,
 		IsVerified:   false,
 		IsModerator:  false,
+
+		IsTimestampSynthesized: synthesized,
 	}
 
-	if messageRenderer.AuthorBadges != nil {
-		for _, entry := range messageRenderer.AuthorBadges {
-			badge := entry.LiveChatAuthorBadgeRenderer
-			if badge.CustomThumbnail != nil {
-				idx.Author.Badge = &types.Badge{
-					Thumbnail: *parseThumbnailToImageItem(badge.CustomThumbnail.Thumbnails, badge.Tooltip),
-					Label:     badge.Tooltip,
-				}
-				idx.IsMembership = true
-			} else {
-				if badge.Icon != nil {
-					switch badge.Icon.IconType {
-					case "OWNER":
-						idx.IsOwner = true
-					case "VERIFIED":
-						idx.IsVerified = true
-					case "MODERATOR":
-						idx.IsModerator = true
-					}
-				}
-			}
+	for _, entry := range messageRenderer.AuthorBadges {
+		badge := parseBadge(entry)
+		idx.Author.Badges = append(idx.Author.Badges, badge)
+
+		switch badge.Type {
+		case types.BadgeOwner:
+			idx.Author.Roles |= types.RoleOwner
+			idx.IsOwner = true
+		case types.BadgeModerator:
+			idx.Author.Roles |= types.RoleModerator
+			idx.IsModerator = true
+		case types.BadgeVerified:
+			idx.Author.Roles |= types.RoleVerified
+			idx.IsVerified = true
+		case types.BadgeArtist:
+			idx.Author.Roles |= types.RoleArtist
+		case types.BadgeMember:
+			idx.Author.Roles |= types.RoleMember
+			idx.IsMembership = true
+		}
+	}
+	for i := range idx.Author.Badges {
+		if idx.Author.Badges[i].Type == types.BadgeMember {
+			idx.Author.Badge = &idx.Author.Badges[i]
 		}
 	}
 
 	// Superchat logic
 	if item.LiveChatPaidStickerRenderer != nil {
 		r := item.LiveChatPaidStickerRenderer
-		idx.SuperChat = &types.SuperChat{
-			Amount: r.PurchaseAmountText.SimpleText,
-			Color:  convertColorToHex6(r.BackgroundColor),
-			Sticker: parseThumbnailToImageItem(
-				r.Sticker.Thumbnails,
-				r.Sticker.Accessibility.AccessibilityData.Label,
-			),
-		}
+		idx.SuperChat = parseSuperChat(r.PurchaseAmountText.SimpleText, types.SuperChatColors{
+			BodyBackground:      types.Color(r.BackgroundColor),
+			AuthorName:          types.Color(r.AuthorNameTextColor),
+			MoneyChipBackground: types.Color(r.MoneyChipBackgroundColor),
+			MoneyChipText:       types.Color(r.MoneyChipTextColor),
+		})
+		idx.SuperChat.Sticker = parseThumbnailToImageItem(
+			r.Sticker.Thumbnails,
+			r.Sticker.Accessibility.AccessibilityData.Label,
+		)
 	} else if item.LiveChatPaidMessageRenderer != nil {
 		r := item.LiveChatPaidMessageRenderer
-		idx.SuperChat = &types.SuperChat{
-			Amount: r.PurchaseAmountText.SimpleText,
-			Color:  convertColorToHex6(r.BodyBackgroundColor),
+		idx.SuperChat = parseSuperChat(r.PurchaseAmountText.SimpleText, types.SuperChatColors{
+			HeaderBackground: types.Color(r.HeaderBackgroundColor),
+			HeaderText:       types.Color(r.HeaderTextColor),
+			BodyBackground:   types.Color(r.BodyBackgroundColor),
+			BodyText:         types.Color(r.BodyTextColor),
+			AuthorName:       types.Color(r.AuthorNameTextColor),
+			Timestamp:        types.Color(r.TimestampColor),
+		})
+	}
+
+	if r := item.LiveChatMembershipItemRenderer; r != nil {
+		idx.Membership = parseMembership(r)
+	}
+
+	// Gifted memberships
+	if r := item.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer; r != nil {
+		count, tier := parseGiftPurchaseText(r.Header.LiveChatSponsorshipsHeaderRenderer.PrimaryText.Runs)
+		idx.Gift = &types.Gift{
+			Kind:   types.GiftPurchase,
+			Gifter: authorNameText,
+			Count:  count,
+			Tier:   tier,
+		}
+	} else if r := item.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer; r != nil {
+		idx.Gift = &types.Gift{
+			Kind:      types.GiftRedemption,
+			Gifter:    parseGiftRedemptionGifter(r.Message.Runs),
+			Recipient: authorNameText,
 		}
 	}
 
 	return &idx
 }
+
+func parseBadge(entry types.AuthorBadge) types.Badge {
+	r := entry.LiveChatAuthorBadgeRenderer
+	badge := types.Badge{Label: r.Tooltip}
+
+	if r.CustomThumbnail != nil {
+		// Only membership badges are custom
+		badge.Type = types.BadgeMember
+		badge.Thumbnail = *parseThumbnailToImageItem(r.CustomThumbnail.Thumbnails, r.Tooltip)
+		badge.Thumbnails = r.CustomThumbnail.Thumbnails
+		badge.Tier, badge.Months = parseBadgeTooltip(r.Tooltip)
+		return badge
+	}
+
+	if r.Icon != nil {
+		badge.Icon = r.Icon.IconType
+		switch r.Icon.IconType {
+		case "OWNER":
+			badge.Type = types.BadgeOwner
+		case "MODERATOR":
+			badge.Type = types.BadgeModerator
+		case "VERIFIED", "CHECK_CIRCLE_THICK":
+			badge.Type = types.BadgeVerified
+		case "OFFICIAL_ARTIST_BADGE":
+			badge.Type = types.BadgeArtist
+		}
+	}
+	return badge
+}
+
+// parseBadgeTooltip reads "Member (2 years)", "Tier (1 year, 3 months)" or
+// "メンバー（6 か月）". New members, e.g. "New member", have no tier or duration.
+func parseBadgeTooltip(tooltip string) (string, int) {
+	m := regexBadgeTooltip.FindStringSubmatch(tooltip)
+	if m == nil {
+		return "", 0
+	}
+	return strings.TrimSpace(m[1]), parseMonths(m[2])
+}
+
+// parseMonths sums the years and months of "2 years", "1 year, 3 months" or
+// "6 か月"
+func parseMonths(text string) int {
+	months := 0
+	for _, d := range regexBadgeDuration.FindAllStringSubmatch(text, -1) {
+		n, _ := strconv.Atoi(d[1])
+		if regexBadgeYears.MatchString(d[2]) {
+			n *= 12
+		}
+		months += n
+	}
+	return months
+}
+
+// parseTimestampUsec falls back to the current time, reporting it as synthesized
+func parseTimestampUsec(usec string) (time.Time, bool) {
+	if ts, err := strconv.ParseInt(usec, 10, 64); err == nil {
+		return time.Unix(ts/1000000, (ts%1000000)*1000), false
+	}
+	return time.Now(), true
+}
+
+// parseSystemItem handles the author-less renderers posted by YouTube itself
+func parseSystemItem(item types.ActionItem) *types.ChatItem {
+	var r *types.LiveChatSystemMessageRenderer
+	system := types.SystemMessage{}
+
+	if item.LiveChatViewerEngagementMessageRenderer != nil {
+		r = item.LiveChatViewerEngagementMessageRenderer
+		system.Kind = types.SystemEngagement
+	} else if item.LiveChatModeChangeMessageRenderer != nil {
+		r = item.LiveChatModeChangeMessageRenderer
+	} else if item.LiveChatPlaceholderItemRenderer != nil {
+		r = item.LiveChatPlaceholderItemRenderer
+		system.Kind = types.SystemPlaceholder
+	} else {
+		return nil
+	}
+
+	if r.Icon != nil {
+		system.Icon = r.Icon.IconType
+	}
+	if r.Message != nil {
+		system.Text = parseMessages(r.Message.Runs)
+	}
+	if r.Text != nil {
+		system.Text = parseMessages(r.Text.Runs)
+	}
+	if r.Subtext != nil {
+		system.Subtext = parseMessages(r.Subtext.Runs)
+	}
+
+	if item.LiveChatModeChangeMessageRenderer != nil {
+		parseModeChange(r, &system)
+	}
+
+	timestamp, synthesized := parseTimestampUsec(r.TimestampUsec)
+	return &types.ChatItem{
+		ID:        r.ID,
+		Message:   system.Text,
+		Timestamp: timestamp,
+		System:    &system,
+
+		IsTimestampSynthesized: synthesized,
+	}
+}
+
+// parseModeChange reads "Slow mode is on" / "Send a message every 30 seconds".
+// The icon names the mode. Only a slow mode interval tells on from off in any
+// language; otherwise a few known words are looked for and the state is left
+// unknown when none is found.
+func parseModeChange(r *types.LiveChatSystemMessageRenderer, system *types.SystemMessage) {
+	text, subtext := "", ""
+	if r.Text != nil {
+		for _, run := range r.Text.Runs {
+			text += run.Text
+		}
+	}
+	if r.Subtext != nil {
+		for _, run := range r.Subtext.Runs {
+			subtext += run.Text
+		}
+	}
+
+	icon := strings.ToUpper(system.Icon)
+	switch {
+	case strings.Contains(icon, "SLOW"):
+		system.Kind = types.SystemSlowMode
+	case strings.Contains(icon, "SUBSCRIBER"):
+		system.Kind = types.SystemSubscribersOnly
+	case strings.Contains(icon, "MEMBER"), strings.Contains(icon, "SPONSOR"):
+		system.Kind = types.SystemMembersOnly
+	}
+
+	switch {
+	case system.Kind == types.SystemSlowMode && strings.ContainsAny(subtext, "0123456789"):
+		system.State = types.ModeOn
+	case regexModeOff.MatchString(text):
+		system.State = types.ModeOff
+	case regexModeOn.MatchString(text):
+		system.State = types.ModeOn
+	}
+
+	if system.Kind == types.SystemSlowMode && system.State == types.ModeOn {
+		if m := regexSlowModeDelay.FindStringSubmatch(subtext); len(m) > 2 {
+			n, _ := strconv.Atoi(m[1])
+			unit := time.Second
+			if u := strings.ToLower(m[2]); strings.HasPrefix(u, "min") || u == "分" {
+				unit = time.Minute
+			}
+			system.Delay = time.Duration(n) * unit
+		}
+	}
+}
+
+func parseMembership(r *types.LiveChatMembershipItemRenderer) *types.Membership {
+	subtext := r.HeaderSubtext.Runs
+	if len(subtext) == 0 && r.HeaderSubtext.SimpleText != "" {
+		subtext = []types.MessageRun{{Text: r.HeaderSubtext.SimpleText}}
+	}
+
+	if r.HeaderPrimaryText != nil {
+		// "Member for 24 months" with the tier as subtext
+		header := r.HeaderPrimaryText.Runs
+		tier := ""
+		for _, run := range subtext {
+			tier += run.Text
+		}
+		return &types.Membership{
+			Kind:   types.MembershipMilestone,
+			Tier:   strings.TrimSpace(tier),
+			Months: parseMembershipMonths(header),
+			Header: parseMessages(header),
+		}
+	}
+
+	// "Welcome to Tier!": the tier is the longest run not padded with spaces.
+	// A single run such as "New member" has no tier of its own.
+	tier := ""
+	if len(subtext) > 1 {
+		for _, run := range subtext {
+			if run.Text == strings.TrimSpace(run.Text) && len(run.Text) > len(tier) {
+				tier = run.Text
+			}
+		}
+	}
+
+	return &types.Membership{
+		Kind:   types.MembershipNew,
+		Tier:   tier,
+		Header: parseMessages(subtext),
+	}
+}
+
+// parseMembershipMonths reads "Member for 24 months" or "Member for 1 year, 3 months"
+func parseMembershipMonths(runs []types.MessageRun) int {
+	text := ""
+	for _, run := range runs {
+		text += run.Text
+	}
+	return parseMonths(text)
+}
+
+// parseGiftPurchaseText reads the count and membership name from runs such as
+// ["Gifted ", "5", " ", "Tier", " memberships"]
+func parseGiftPurchaseText(runs []types.MessageRun) (int, string) {
+	for i, run := range runs {
+		count, err := strconv.Atoi(strings.ReplaceAll(strings.TrimSpace(run.Text), ",", ""))
+		if err != nil {
+			continue
+		}
+
+		tier := ""
+		// The last run is the trailing "memberships"
+		for j := i + 1; j < len(runs)-1; j++ {
+			if text := strings.TrimSpace(runs[j].Text); text != "" {
+				tier = text
+				break
+			}
+		}
+		return count, tier
+	}
+	return 0, ""
+}
+
+// parseGiftRedemptionGifter reads the gifter from runs such as
+// ["received a gift membership by ", "gifterName"]
+func parseGiftRedemptionGifter(runs []types.MessageRun) string {
+	for i := len(runs) - 1; i >= 0; i-- {
+		if text := strings.TrimSpace(runs[i].Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
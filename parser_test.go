@@ -2,8 +2,10 @@ package youtubechat
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -53,6 +55,13 @@ func TestParseChatData(t *testing.T) {
 				if msg.EmojiItem.EmojiText != "👏" {
 					t.Errorf("Expected emoji text 👏, got %s", msg.EmojiItem.EmojiText)
 				}
+				emoji := msg.EmojiItem
+				if len(emoji.Shortcuts) != 2 || len(emoji.SearchTerms) != 3 || len(emoji.VariantIDs) != 6 || !emoji.SupportsSkinTone {
+					t.Errorf("Expected full emoji metadata, got %+v", emoji)
+				}
+				if emoji.Label != "👏" || emoji.ID != "👏" {
+					t.Errorf("Unexpected label %s or id %s", emoji.Label, emoji.ID)
+				}
 			},
 		},
 		{
@@ -65,6 +74,9 @@ func TestParseChatData(t *testing.T) {
 				if msg.EmojiItem.EmojiText != "👏🏿" {
 					t.Errorf("Expected emoji text 👏🏿, got %s", msg.EmojiItem.EmojiText)
 				}
+				if msg.EmojiItem.Unicode != "👏🏿" {
+					t.Errorf("Expected unicode 👏🏿, got %s", msg.EmojiItem.Unicode)
+				}
 			},
 		},
 		{
@@ -80,6 +92,36 @@ func TestParseChatData(t *testing.T) {
 				if msg.EmojiItem.EmojiText != ":customEmoji:" {
 					t.Errorf("Expected :customEmoji:, got %s", msg.EmojiItem.EmojiText)
 				}
+				if len(msg.EmojiItem.Thumbnails) != 2 || msg.EmojiItem.Thumbnails[1].Width != 48 {
+					t.Errorf("Expected both thumbnail sizes, got %+v", msg.EmojiItem.Thumbnails)
+				}
+			},
+		},
+		{
+			name:             "Rich Text",
+			filename:         "get_live_chat.rich-text.json",
+			expectedCont:     "test-continuation:01",
+			expectedNumItems: 1,
+			validateItems: func(t *testing.T, items []types.ChatItem) {
+				msg := items[0].Message
+				if len(msg) != 10 {
+					t.Fatalf("Expected 10 message parts, got %d", len(msg))
+				}
+				if msg[0].Kind != types.RunText || msg[0].URL != "" {
+					t.Errorf("Unexpected text %+v", msg[0])
+				}
+				if m := msg[1]; m.Kind != types.RunMention || m.ChannelID != "UCstreamerChannelId" || m.URL != "https://www.youtube.com/@streamerHandle" {
+					t.Errorf("Unexpected mention %+v", m)
+				}
+				if m := msg[3]; m.Kind != types.RunLink || m.URL != "https://example.com/page" {
+					t.Errorf("Unexpected link %+v", m)
+				}
+				if m := msg[5]; m.Kind != types.RunLink || m.VideoID != "dchqdFOW8EI" || m.URL != "https://www.youtube.com/watch?v=dchqdFOW8EI" {
+					t.Errorf("Unexpected video link %+v", m)
+				}
+				if !msg[7].Bold || msg[7].Italic || !msg[9].Italic {
+					t.Errorf("Unexpected formatting %+v %+v", msg[7], msg[9])
+				}
 			},
 		},
 		{
@@ -94,6 +136,16 @@ func TestParseChatData(t *testing.T) {
 				if items[0].Author.Badge.Label != "メンバー（6 か月）" {
 					t.Errorf("Expected badge label match")
 				}
+				author := items[0].Author
+				if len(author.Badges) != 1 || author.Badge != &author.Badges[0] {
+					t.Fatalf("Expected the membership badge in Badges, got %+v", author.Badges)
+				}
+				if b := author.Badges[0]; b.Type != types.BadgeMember || b.Tier != "メンバー" || b.Months != 6 || len(b.Thumbnails) != 2 {
+					t.Errorf("Unexpected badge %+v", b)
+				}
+				if author.Roles != types.RoleMember {
+					t.Errorf("Expected member role, got %s", author.Roles)
+				}
 			},
 		},
 		{
@@ -110,6 +162,34 @@ func TestParseChatData(t *testing.T) {
 				if len(items[0].Message) != 2 {
 					t.Errorf("Expected 2 message parts, got %d", len(items[0].Message))
 				}
+				m := items[0].Membership
+				if m == nil {
+					t.Fatal("Expected Membership")
+				}
+				if m.Kind != types.MembershipNew || m.Tier != "上級エンジニア" || m.Months != 0 {
+					t.Errorf("Unexpected membership %+v", m)
+				}
+			},
+		},
+		{
+			name:             "Membership Milestone",
+			filename:         "get_live_chat.milestone-member.json",
+			expectedCont:     "test-continuation:01",
+			expectedNumItems: 1,
+			validateItems: func(t *testing.T, items []types.ChatItem) {
+				m := items[0].Membership
+				if m == nil {
+					t.Fatal("Expected Membership")
+				}
+				if m.Kind != types.MembershipMilestone || m.Tier != "tierName" || m.Months != 24 {
+					t.Errorf("Unexpected membership %+v", m)
+				}
+				if len(m.Header) != 3 {
+					t.Errorf("Expected 3 header parts, got %d", len(m.Header))
+				}
+				if len(items[0].Message) != 1 || items[0].Message[0].Text != "Two years already!" {
+					t.Errorf("Expected member comment as message, got %+v", items[0].Message)
+				}
 			},
 		},
 		{
@@ -127,6 +207,16 @@ func TestParseChatData(t *testing.T) {
 				if items[0].SuperChat.Color != "#FFCA28" {
 					t.Errorf("Expected color #FFCA28, got %s", items[0].SuperChat.Color)
 				}
+				if money := items[0].SuperChat.Money; money.Currency != "JPY" || money.Micros != 1000000000 {
+					t.Errorf("Expected 1000 JPY, got %+v", money)
+				}
+				sc := items[0].SuperChat
+				if sc.Tier != types.SuperChatTierYellow || sc.Duration != 5*time.Minute {
+					t.Errorf("Expected yellow tier for 5 minutes, got %s for %v", sc.Tier, sc.Duration)
+				}
+				if sc.Colors.HeaderBackground.HexAlpha() != "#FFB300FF" || sc.Colors.BodyText.HexAlpha() != "#000000DF" {
+					t.Errorf("Unexpected colors %+v", sc.Colors)
+				}
 			},
 		},
 		{
@@ -145,8 +235,47 @@ func TestParseChatData(t *testing.T) {
 				if sc.Amount != "￥90" {
 					t.Errorf("Expected amount ￥90, got %s", sc.Amount)
 				}
+				if sc.Tier != types.SuperChatTierBlue || sc.Colors.MoneyChipBackground.Hex() != "#1E88E5" {
+					t.Errorf("Unexpected sticker tier %s, colors %+v", sc.Tier, sc.Colors)
+				}
+			},
+		},
+		{
+			name:             "Gifted Memberships",
+			filename:         "get_live_chat.gift.json",
+			expectedCont:     "test-continuation:01",
+			expectedNumItems: 2,
+			validateItems: func(t *testing.T, items []types.ChatItem) {
+				purchase := items[0].Gift
+				if purchase == nil {
+					t.Fatal("Expected Gift on purchase")
+				}
+				if purchase.Kind != types.GiftPurchase || purchase.Count != 5 || purchase.Tier != "tierName" || purchase.Gifter != "gifterName" {
+					t.Errorf("Unexpected purchase %+v", purchase)
+				}
+				if items[0].Author.Name != "gifterName" || !items[0].IsMembership {
+					t.Errorf("Unexpected gifter %+v", items[0].Author)
+				}
+
+				redemption := items[1].Gift
+				if redemption == nil {
+					t.Fatal("Expected Gift on redemption")
+				}
+				if redemption.Kind != types.GiftRedemption || redemption.Recipient != "recipientName" || redemption.Gifter != "gifterName" {
+					t.Errorf("Unexpected redemption %+v", redemption)
+				}
+				if items[1].Author.ChannelID != "recipientChannelId" {
+					t.Errorf("Expected recipient as author, got %s", items[1].Author.ChannelID)
+				}
 			},
 		},
+		{
+			// Author-less items stay off the legacy item list
+			name:             "System Messages",
+			filename:         "get_live_chat.system.json",
+			expectedCont:     "test-continuation:01",
+			expectedNumItems: 0,
+		},
 		{
 			name:             "From Verified User",
 			filename:         "get_live_chat.from-verified.json",
@@ -156,6 +285,9 @@ func TestParseChatData(t *testing.T) {
 				if !items[0].IsVerified {
 					t.Error("Expected IsVerified true")
 				}
+				if author := items[0].Author; author.Roles != types.RoleVerified || author.Badges[0].Type != types.BadgeVerified || author.Badges[0].Icon != "VERIFIED" {
+					t.Errorf("Unexpected roles %s and badges %+v", author.Roles, author.Badges)
+				}
 			},
 		},
 		{
@@ -167,6 +299,9 @@ func TestParseChatData(t *testing.T) {
 				if !items[0].IsModerator {
 					t.Error("Expected IsModerator true")
 				}
+				if author := items[0].Author; author.Roles != types.RoleModerator || author.Badges[0].Type != types.BadgeModerator || author.Badges[0].Icon != "MODERATOR" {
+					t.Errorf("Unexpected roles %s and badges %+v", author.Roles, author.Badges)
+				}
 			},
 		},
 		{
@@ -178,6 +313,32 @@ func TestParseChatData(t *testing.T) {
 				if !items[0].IsOwner {
 					t.Error("Expected IsOwner true")
 				}
+				if author := items[0].Author; author.Roles != types.RoleOwner || author.Badges[0].Type != types.BadgeOwner || author.Badges[0].Icon != "OWNER" {
+					t.Errorf("Unexpected roles %s and badges %+v", author.Roles, author.Badges)
+				}
+			},
+		},
+		{
+			name:             "Replay",
+			filename:         "get_live_chat.replay.json",
+			expectedCont:     "test-continuation:01",
+			expectedNumItems: 2,
+			validateItems: func(t *testing.T, items []types.ChatItem) {
+				item := items[0]
+				if !item.HasOffset || item.Offset != 12345*time.Millisecond {
+					t.Errorf("Expected offset 12.345s, got %v (%v)", item.Offset, item.HasOffset)
+				}
+				if item.IsTimestampSynthesized || item.Timestamp.UnixMicro() != 1609459200000000 {
+					t.Errorf("Unexpected timestamp %v", item.Timestamp)
+				}
+
+				item = items[1]
+				if !item.HasOffset || item.Offset != -2*time.Second {
+					t.Errorf("Expected offset -2s, got %v (%v)", item.Offset, item.HasOffset)
+				}
+				if !item.IsTimestampSynthesized {
+					t.Error("Expected missing timestamp to be synthesized")
+				}
 			},
 		},
 		{
@@ -243,6 +404,34 @@ func TestGetOptionsFromLivePage(t *testing.T) {
 		if opts.LiveID == "" || opts.ApiKey == "" || opts.ClientVersion == "" || opts.Continuation == "" {
 			t.Errorf("Missing fields in opts: %+v", opts)
 		}
+		if opts.Channel.ID != "UCxkOLgdNumvVIQqn5ps_bJA" {
+			t.Errorf("Expected channel ID UCxkOLgdNumvVIQqn5ps_bJA, got %s", opts.Channel.ID)
+		}
+		if !strings.HasSuffix(opts.TopChatContinuation, "IIBA%3D%3D") {
+			t.Errorf("Unexpected top chat continuation %s", opts.TopChatContinuation)
+		}
+		if !strings.HasSuffix(opts.LiveChatContinuation, "IIAQ%3D%3D") {
+			t.Errorf("Unexpected live chat continuation %s", opts.LiveChatContinuation)
+		}
+	})
+
+	t.Run("Comments sort menu", func(t *testing.T) {
+		menu := func(first, second string) string {
+			return `"sortFilterSubMenuRenderer":{"subMenuItems":[` +
+				`{"continuation":{"reloadContinuationData":{"continuation":"` + first + `"}}},` +
+				`{"continuation":{"reloadContinuationData":{"continuation":"` + second + `"}}}]}`
+		}
+		data := `"engagementPanels":[{` + menu("topComments", "newComments") + `}],` +
+			`"conversationBar":{"liveChatRenderer":{"continuations":[{"reloadContinuationData":{"continuation":"chat"}}],` +
+			`"header":{"liveChatHeaderRenderer":{"viewSelector":{` + menu("top", "live") + `}}}}}`
+
+		top, live := parseViewSelectorContinuations(data)
+		if top != "top" || live != "live" {
+			t.Errorf("Expected the chat header's continuations, got %s / %s", top, live)
+		}
+		if top, live := parseViewSelectorContinuations(`"engagementPanels":[{` + menu("topComments", "newComments") + `}]`); top != "" || live != "" {
+			t.Errorf("Expected no continuations without a chat, got %s / %s", top, live)
+		}
 	})
 
 	t.Run("Replay (Finished)", func(t *testing.T) {
@@ -257,6 +446,9 @@ func TestGetOptionsFromLivePage(t *testing.T) {
 			// checking actual error message might depend on the liveId pulled from regex
 			// t.Logf("Error: %v", err)
 		}
+		if start := parseStartTime(string(data)); !start.Equal(time.Date(2021, 11, 23, 5, 23, 44, 0, time.UTC)) {
+			t.Errorf("Unexpected start time %v", start)
+		}
 	})
 
 	t.Run("No such Live", func(t *testing.T) {
@@ -270,3 +462,639 @@ func TestGetOptionsFromLivePage(t *testing.T) {
 		}
 	})
 }
+
+func TestSelectChatMode(t *testing.T) {
+	opts := types.FetchOptions{
+		Continuation:         "first",
+		TopChatContinuation:  "top",
+		LiveChatContinuation: "live",
+	}
+
+	tests := []struct {
+		mode     types.ChatMode
+		expected string
+	}{
+		{types.ChatModeDefault, "first"},
+		{types.ChatModeTop, "top"},
+		{types.ChatModeLive, "live"},
+	}
+
+	for _, tt := range tests {
+		selected, err := SelectChatMode(opts, tt.mode)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if selected.Continuation != tt.expected {
+			t.Errorf("Expected continuation %s, got %s", tt.expected, selected.Continuation)
+		}
+	}
+
+	t.Run("Missing view selector", func(t *testing.T) {
+		_, err := SelectChatMode(types.FetchOptions{Continuation: "first"}, types.ChatModeLive)
+		if err == nil || err.Error() != "Live chat continuation was not found" {
+			t.Errorf("Expected 'Live chat continuation was not found', got %v", err)
+		}
+	})
+}
+
+func TestGetChannelFromPage(t *testing.T) {
+	for _, filename := range []string{"live-page.html", "replay_page.html", "no_live_page.html"} {
+		t.Run(filename, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+			if err != nil {
+				t.Fatal(err)
+			}
+			channel, err := GetChannelFromPage(string(data))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if channel.ID != "UCxkOLgdNumvVIQqn5ps_bJA" {
+				t.Errorf("Expected channel ID UCxkOLgdNumvVIQqn5ps_bJA, got %s", channel.ID)
+			}
+			if channel.Title != "創好リナ [バーチャルイキリプログラマ]" {
+				t.Errorf("Unexpected channel title %s", channel.Title)
+			}
+		})
+	}
+
+	t.Run("Handle", func(t *testing.T) {
+		data := `"microformat":{"ownerProfileUrl":"http://www.youtube.com/@handle","externalChannelId":"UCxkOLgdNumvVIQqn5ps_bJA"},` +
+			`"videoDetails":{"videoId":"liveId","channelId":"UCxkOLgdNumvVIQqn5ps_bJA","author":"Tom \u0026 Jerry"}`
+		channel, err := GetChannelFromPage(data)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if channel.Handle != "@handle" {
+			t.Errorf("Expected handle @handle, got %s", channel.Handle)
+		}
+		if channel.Title != "Tom & Jerry" {
+			t.Errorf("Expected title 'Tom & Jerry', got %s", channel.Title)
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := GetChannelFromPage("<html></html>")
+		if err == nil || err.Error() != "Channel ID was not found" {
+			t.Errorf("Expected 'Channel ID was not found', got %v", err)
+		}
+	})
+}
+
+func TestGetOptionsFromNextResponse(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "next.live.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Normal", func(t *testing.T) {
+		var res types.NextResponse
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatalf("Failed to unmarshal JSON: %v", err)
+		}
+
+		opts, err := GetOptionsFromNextResponse(res)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := types.FetchOptions{
+			LiveID:               "liveId",
+			Continuation:         "test-continuation:top",
+			TopChatContinuation:  "test-continuation:top",
+			LiveChatContinuation: "test-continuation:live",
+			Channel: types.Channel{
+				ID:     "UCxkOLgdNumvVIQqn5ps_bJA",
+				Handle: "@handle",
+				Title:  "channelTitle",
+			},
+		}
+		if opts != expected {
+			t.Errorf("Expected %+v, got %+v", expected, opts)
+		}
+	})
+
+	t.Run("Replay (Finished)", func(t *testing.T) {
+		var res types.NextResponse
+		json.Unmarshal(data, &res)
+		res.Contents.TwoColumnWatchNextResults.ConversationBar.LiveChatRenderer.IsReplay = true
+
+		_, err := GetOptionsFromNextResponse(res)
+		if err == nil || err.Error() != "liveId is finished live" {
+			t.Errorf("Expected 'liveId is finished live', got %v", err)
+		}
+	})
+
+	t.Run("No chat", func(t *testing.T) {
+		var res types.NextResponse
+		json.Unmarshal(data, &res)
+		res.Contents.TwoColumnWatchNextResults.ConversationBar.LiveChatRenderer = nil
+
+		_, err := GetOptionsFromNextResponse(res)
+		var stateErr *PageStateError
+		if !errors.As(err, &stateErr) || stateErr.State != PageStateChatDisabled {
+			t.Errorf("Expected chat disabled, got %v", err)
+		}
+	})
+}
+
+func TestParseLiveChatPage(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "live_chat_page.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actions, continuation, err := ParseLiveChatPage(string(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if continuation != "test-continuation:backlog" {
+			t.Errorf("Expected continuation test-continuation:backlog, got %s", continuation)
+		}
+		items := actions.Items
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 items, got %d", len(items))
+		}
+		if items[0].ID != "id" || items[1].ID != "ownerId" {
+			t.Errorf("Unexpected item order: %s, %s", items[0].ID, items[1].ID)
+		}
+		for _, item := range items {
+			if !item.IsBacklog {
+				t.Errorf("Expected %s to be flagged IsBacklog", item.ID)
+			}
+		}
+	})
+
+	t.Run("Raw JSON", func(t *testing.T) {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "live_chat_page.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actions, _, err := ParseLiveChatPage(string(data), WithRawJSON())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, item := range actions.Items {
+			if !strings.Contains(string(item.Raw), `"id":"`+item.ID+`"`) && !strings.Contains(string(item.Raw), `"id": "`+item.ID+`"`) {
+				t.Errorf("Expected the raw action of %s, got %s", item.ID, item.Raw)
+			}
+		}
+	})
+
+	t.Run("Not a live chat page", func(t *testing.T) {
+		_, _, err := ParseLiveChatPage("<html></html>")
+		if err == nil || err.Error() != "Initial data was not found" {
+			t.Errorf("Expected 'Initial data was not found', got %v", err)
+		}
+	})
+}
+
+func loadChatResponse(t *testing.T, filename string) types.GetLiveChatResponse {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var res types.GetLiveChatResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+	return res
+}
+
+func TestParseChatActions(t *testing.T) {
+	t.Run("Deleted", func(t *testing.T) {
+		actions, continuation := ParseChatActions(loadChatResponse(t, "get_live_chat.deleted.json"))
+		if continuation != "test-continuation:01" {
+			t.Errorf("Expected continuation test-continuation:01, got %s", continuation)
+		}
+		if len(actions.Items) != 0 {
+			t.Errorf("Expected no chat items, got %d", len(actions.Items))
+		}
+		if len(actions.Deletions) != 2 {
+			t.Fatalf("Expected 2 deletions, got %d", len(actions.Deletions))
+		}
+
+		byItem := actions.Deletions[0]
+		if byItem.TargetItemID != "id" || byItem.AuthorChannelID != "" {
+			t.Errorf("Unexpected item deletion %+v", byItem)
+		}
+		if len(byItem.Message) != 1 || byItem.Message[0].Text != "[message deleted]" {
+			t.Errorf("Unexpected deletion message %+v", byItem.Message)
+		}
+
+		byAuthor := actions.Deletions[1]
+		if byAuthor.AuthorChannelID != "channelId" || byAuthor.TargetItemID != "" {
+			t.Errorf("Unexpected author deletion %+v", byAuthor)
+		}
+	})
+
+	t.Run("Replaced", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.replaced.json"))
+		if len(actions.Items) != 0 {
+			t.Errorf("Expected no chat items, got %d", len(actions.Items))
+		}
+		if len(actions.Replacements) != 1 {
+			t.Fatalf("Expected 1 replacement, got %d", len(actions.Replacements))
+		}
+
+		replacement := actions.Replacements[0]
+		if replacement.TargetItemID != "id" || replacement.Item.ID != "id" {
+			t.Errorf("Unexpected replacement target %s / item %s", replacement.TargetItemID, replacement.Item.ID)
+		}
+		if len(replacement.Item.Message) != 1 || replacement.Item.Message[0].Text != "Hello, Replaced!" {
+			t.Errorf("Unexpected replacement message %+v", replacement.Item.Message)
+		}
+	})
+
+	t.Run("Ticker", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.ticker.json"))
+		if len(actions.Items) != 0 {
+			t.Errorf("Expected no chat items, got %d", len(actions.Items))
+		}
+		if len(actions.Tickers) != 4 {
+			t.Fatalf("Expected 4 tickers, got %d", len(actions.Tickers))
+		}
+
+		paid := actions.Tickers[0]
+		if paid.Kind != types.TickerPaidMessage || paid.ID != "tickerId" {
+			t.Errorf("Unexpected paid message ticker %+v", paid)
+		}
+		if paid.Amount != "￥1,000" || paid.StartBackgroundColor != "#FFCA28" || paid.EndBackgroundColor != "#FFB300" {
+			t.Errorf("Unexpected paid message ticker style %s %s %s", paid.Amount, paid.StartBackgroundColor, paid.EndBackgroundColor)
+		}
+		if paid.Duration != 120*time.Second || paid.FullDuration != 120*time.Second {
+			t.Errorf("Unexpected durations %v / %v", paid.Duration, paid.FullDuration)
+		}
+		if paid.LinkedItemID != "id" || paid.LinkedItem == nil || paid.LinkedItem.SuperChat == nil {
+			t.Errorf("Expected linked super chat 'id', got %s", paid.LinkedItemID)
+		}
+		if paid.Author.Name != "authorName" || paid.Author.ChannelID != "channelId" {
+			t.Errorf("Unexpected author %+v", paid.Author)
+		}
+
+		sticker := actions.Tickers[1]
+		if sticker.Kind != types.TickerPaidSticker || sticker.Sticker == nil || sticker.Sticker.Alt != "superSticker" {
+			t.Errorf("Unexpected sticker ticker %+v", sticker)
+		}
+
+		member := actions.Tickers[2]
+		if member.Kind != types.TickerMembership || member.Amount != "メンバー" || member.LinkedItemID != "id" {
+			t.Errorf("Unexpected membership ticker %+v", member)
+		}
+
+		gift := actions.Tickers[3]
+		if gift.Kind != types.TickerGift || gift.Amount != "5" {
+			t.Errorf("Unexpected gift ticker %+v", gift)
+		}
+	})
+
+	t.Run("Poll", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.poll.json"))
+		if len(actions.Polls) != 3 {
+			t.Fatalf("Expected 3 poll events, got %d", len(actions.Polls))
+		}
+
+		created := actions.Polls[0]
+		if created.Kind != types.PollCreated || created.Poll.ID != "pollId" || created.Poll.PanelID != "panelId" {
+			t.Errorf("Unexpected created poll %+v", created)
+		}
+		if created.Poll.Question[0].Text != "Question?" || created.Poll.Author.Name != "authorName" {
+			t.Errorf("Unexpected poll header %+v", created.Poll)
+		}
+		if created.Poll.State != types.PollOpen || len(created.Poll.Choices) != 2 {
+			t.Errorf("Unexpected poll state %+v", created.Poll)
+		}
+
+		updated := actions.Polls[1]
+		if updated.Kind != types.PollUpdated || updated.Poll.TotalVotes != 1234 {
+			t.Errorf("Expected 1234 votes, got %d", updated.Poll.TotalVotes)
+		}
+		yes := updated.Poll.Choices[0]
+		if yes.Text[0].Text != "Yes" || yes.VotePercentage != "75%" || yes.VoteRatio != 0.75 {
+			t.Errorf("Unexpected choice %+v", yes)
+		}
+
+		ended := actions.Polls[2]
+		if ended.Kind != types.PollEnded || ended.Poll.PanelID != "panelId" || ended.Poll.State != types.PollClosed {
+			t.Errorf("Unexpected ended poll %+v", ended)
+		}
+	})
+
+	t.Run("System", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.system.json"))
+		if len(actions.Items) != 0 {
+			t.Errorf("Expected no chat items, got %d", len(actions.Items))
+		}
+		items := actions.System
+		if len(items) != 5 {
+			t.Fatalf("Expected 5 system items, got %d", len(items))
+		}
+		for i, item := range items {
+			if item.System == nil {
+				t.Fatalf("Expected System on item %d", i)
+			}
+		}
+		if s := items[0].System; s.Kind != types.SystemEngagement || s.Icon != "YOUTUBE_ROUND" || len(items[0].Message) != 1 {
+			t.Errorf("Unexpected engagement %+v", s)
+		}
+		if s := items[1].System; s.Kind != types.SystemSlowMode || s.State != types.ModeOn || s.Delay != 30*time.Second {
+			t.Errorf("Unexpected slow mode on %+v", s)
+		}
+		if s := items[2].System; s.Kind != types.SystemSlowMode || s.State != types.ModeOff || s.Delay != 0 {
+			t.Errorf("Unexpected slow mode off %+v", s)
+		}
+		if s := items[3].System; s.Kind != types.SystemMembersOnly || s.State != types.ModeOn || len(s.Subtext) != 1 {
+			t.Errorf("Unexpected members-only %+v", s)
+		}
+		if s := items[4].System; s.Kind != types.SystemPlaceholder || items[4].ID != "placeholderId" {
+			t.Errorf("Unexpected placeholder %+v", items[4])
+		}
+		if events := actions.Events(); len(events) != 5 {
+			t.Errorf("Expected system items among the events, got %d", len(events))
+		}
+	})
+
+	t.Run("Localized system", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.system-localized.json"))
+		items := actions.System
+		if len(items) != 3 {
+			t.Fatalf("Expected 3 system items, got %d", len(items))
+		}
+		if s := items[0].System; s.Kind != types.SystemSlowMode || s.State != types.ModeOn || s.Delay != 30*time.Second {
+			t.Errorf("Unexpected slow mode on %+v", s)
+		}
+		if s := items[1].System; s.Kind != types.SystemSlowMode || s.State != types.ModeUnknown || s.Delay != 0 {
+			t.Errorf("Unexpected slow mode off %+v", s)
+		}
+		if s := items[2].System; s.Kind != types.SystemMembersOnly || s.State != types.ModeOff {
+			t.Errorf("Unexpected members-only %+v", s)
+		}
+	})
+
+	t.Run("Banner", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.banner.json"))
+		if len(actions.Items) != 0 {
+			t.Errorf("Expected no chat items, got %d", len(actions.Items))
+		}
+		if len(actions.Banners) != 4 {
+			t.Fatalf("Expected 4 banner events, got %d", len(actions.Banners))
+		}
+
+		pinned := actions.Banners[0]
+		if pinned.Kind != types.BannerAdded || pinned.Banner.ID != "pinnedId" || pinned.Banner.Kind != types.BannerPinnedMessage {
+			t.Errorf("Unexpected pinned banner %+v", pinned)
+		}
+		if pinned.Banner.Item == nil || pinned.Banner.Item.ID != "id" {
+			t.Error("Expected pinned chat item 'id'")
+		}
+		if len(pinned.Banner.Header) != 2 || pinned.Banner.Header[1].Text != "ownerName" {
+			t.Errorf("Unexpected header %+v", pinned.Banner.Header)
+		}
+
+		summary := actions.Banners[1].Banner
+		if summary.Kind != types.BannerChatSummary || summary.Text[0].Text != "Chat summary" {
+			t.Errorf("Unexpected summary banner %+v", summary)
+		}
+
+		redirect := actions.Banners[2].Banner
+		if redirect.Kind != types.BannerRedirect || redirect.Thumbnail.URL != "https://raider.thumbnail.url" {
+			t.Errorf("Unexpected redirect banner %+v", redirect)
+		}
+
+		removed := actions.Banners[3]
+		if removed.Kind != types.BannerRemoved || removed.Banner.ID != "pinnedId" {
+			t.Errorf("Unexpected removed banner %+v", removed)
+		}
+
+		if len(actions.Redirects) != 1 || actions.Redirects[0].Source.Title != "raiderName" {
+			t.Errorf("Expected raid from raiderName, got %+v", actions.Redirects)
+		}
+	})
+
+	t.Run("Unhandled", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.unknown.json"))
+		if len(actions.Items) != 1 {
+			t.Fatalf("Expected 1 chat item, got %d", len(actions.Items))
+		}
+		if actions.Items[0].Raw != nil {
+			t.Errorf("Expected no raw action on the item, got %s", actions.Items[0].Raw)
+		}
+
+		if len(actions.Banners) != 0 {
+			t.Errorf("Expected no banner events, got %d", len(actions.Banners))
+		}
+
+		if len(actions.Unhandled) != 3 {
+			t.Fatalf("Expected 3 unhandled actions, got %d", len(actions.Unhandled))
+		}
+		renderer := actions.Unhandled[0]
+		if renderer.Key != "addChatItemAction" || renderer.RendererKey != "liveChatFooRenderer" {
+			t.Errorf("Unexpected keys %s / %s", renderer.Key, renderer.RendererKey)
+		}
+		if !strings.Contains(string(renderer.Renderer), `"fooId"`) || !strings.Contains(string(renderer.Raw), `"clickTrackingParams"`) {
+			t.Errorf("Unexpected JSON %s / %s", renderer.Renderer, renderer.Raw)
+		}
+
+		action := actions.Unhandled[1]
+		if action.Key != "fooAction" || action.RendererKey != "" || !strings.Contains(string(action.Renderer), `"bar"`) {
+			t.Errorf("Unexpected unhandled action %+v", action)
+		}
+
+		banner := actions.Unhandled[2]
+		if banner.Key != "addBannerToLiveChatCommand" || banner.RendererKey != "liveChatBannerFooRenderer" {
+			t.Errorf("Unexpected keys %s / %s", banner.Key, banner.RendererKey)
+		}
+		if !strings.Contains(string(banner.Renderer), `"bannerFooId"`) {
+			t.Errorf("Unexpected banner renderer %s", banner.Renderer)
+		}
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		actions, _ := ParseChatActions(loadChatResponse(t, "get_live_chat.redirect.json"))
+		if len(actions.Banners) != 2 {
+			t.Errorf("Expected redirects to stay banners, got %d", len(actions.Banners))
+		}
+		if len(actions.Redirects) != 2 {
+			t.Fatalf("Expected 2 redirects, got %d", len(actions.Redirects))
+		}
+
+		raid := actions.Redirects[0]
+		if raid.Kind != types.RedirectIncoming || raid.ID != "raidId" {
+			t.Errorf("Unexpected raid %+v", raid)
+		}
+		if raid.Source.ID != "UCraiderChannelId" || raid.Source.Handle != "@raiderHandle" {
+			t.Errorf("Unexpected raid source %+v", raid.Source)
+		}
+		if raid.Avatar == nil || raid.Avatar.URL != "https://raider.thumbnail.url" || len(raid.Message) != 2 {
+			t.Errorf("Unexpected raid banner %+v", raid)
+		}
+
+		outgoing := actions.Redirects[1]
+		if outgoing.Kind != types.RedirectOutgoing || outgoing.Target.Handle != "@targetHandle" || outgoing.TargetVideoID != "targetVideo" {
+			t.Errorf("Unexpected outgoing redirect %+v", outgoing)
+		}
+		if outgoing.Source != (types.Channel{}) {
+			t.Errorf("Expected source left to LiveChat, got %+v", outgoing.Source)
+		}
+	})
+}
+
+func TestChatActionsEvents(t *testing.T) {
+	var actions types.ChatActions
+	actions.Add(types.Deletion{TargetItemID: "a"})
+	actions.Add(types.ChatItem{ID: "b"})
+	actions.Add(types.CustomEvent{Key: "ignored"})
+	// Appended by hand, after the added events
+	actions.Items = append(actions.Items, types.ChatItem{ID: "c"})
+	actions.Deletions = append(actions.Deletions, types.Deletion{TargetItemID: "d"})
+
+	var got []string
+	for _, event := range actions.Events() {
+		switch e := event.(type) {
+		case types.ChatItem:
+			got = append(got, e.ID)
+		case types.Deletion:
+			got = append(got, e.TargetItemID)
+		default:
+			t.Errorf("Unexpected event %T", event)
+		}
+	}
+	if strings.Join(got, "") != "abcd" {
+		t.Errorf("Expected events a b c d, got %v", got)
+	}
+}
+
+func TestParseBadgeTooltip(t *testing.T) {
+	tests := []struct {
+		tooltip string
+		tier    string
+		months  int
+	}{
+		{"New member", "", 0},
+		{"Member (1 month)", "Member", 1},
+		{"Member (6 months)", "Member", 6},
+		{"Member (2 years)", "Member", 24},
+		{"Super Fan (1 year, 3 months)", "Super Fan", 15},
+		{"メンバー（6 か月）", "メンバー", 6},
+		{"メンバー（2 年）", "メンバー", 24},
+		{"Miembro (1 año)", "Miembro", 12},
+		{"Membro (5 meses)", "Membro", 5},
+		{"회원(3개월)", "회원", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tooltip, func(t *testing.T) {
+			tier, months := parseBadgeTooltip(tt.tooltip)
+			if tier != tt.tier || months != tt.months {
+				t.Errorf("Expected %s for %d months, got %s for %d", tt.tier, tt.months, tier, months)
+			}
+		})
+	}
+}
+
+func TestParseMembership(t *testing.T) {
+	runs := func(texts ...string) []types.MessageRun {
+		var runs []types.MessageRun
+		for _, text := range texts {
+			runs = append(runs, types.MessageRun{Text: text})
+		}
+		return runs
+	}
+
+	tests := []struct {
+		name       string
+		simpleText string
+		subtext    []types.MessageRun
+		header     []types.MessageRun
+		tier       string
+		months     int
+	}{
+		{"Tier run", "", runs("Welcome to ", "Gold", "!"), nil, "Gold", 0},
+		{"Simple text", "Welcome to Gold!", nil, nil, "", 0},
+		{"Single run", "", runs("New member"), nil, "", 0},
+		{"Milestone", "Gold", nil, runs("Member for ", "24", " months"), "Gold", 24},
+		{"Milestone years and months", "Gold", nil, runs("Member for 1 year, 3 months"), "Gold", 15},
+		{"Milestone in Spanish", "Oro", nil, runs("Miembro durante 2 años"), "Oro", 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r types.LiveChatMembershipItemRenderer
+			r.HeaderSubtext.SimpleText = tt.simpleText
+			r.HeaderSubtext.Runs = tt.subtext
+			if tt.header != nil {
+				r.HeaderPrimaryText = &struct {
+					Runs []types.MessageRun `json:"runs"`
+				}{Runs: tt.header}
+			}
+
+			m := parseMembership(&r)
+			if m.Tier != tt.tier || m.Months != tt.months {
+				t.Errorf("Expected %q for %d months, got %q for %d", tt.tier, tt.months, m.Tier, m.Months)
+			}
+		})
+	}
+}
+
+func TestRole(t *testing.T) {
+	roles := types.RoleModerator | types.RoleMember
+	if !roles.IsModerator() || !roles.IsMember() || roles.IsOwner() || !roles.CanModerate() {
+		t.Errorf("Unexpected helpers for %s", roles)
+	}
+	if roles.String() != "moderator|member" {
+		t.Errorf("Expected moderator|member, got %s", roles)
+	}
+	if types.Role(0).String() != "viewer" || types.Role(0).CanModerate() {
+		t.Error("Expected a plain viewer")
+	}
+}
+
+func TestEmojiBestImage(t *testing.T) {
+	emoji := types.EmojiItem{Thumbnails: []types.Thumbnail{
+		{URL: "24", Width: 24, Height: 24},
+		{URL: "48", Width: 48, Height: 48},
+		{URL: "96", Width: 96, Height: 96},
+	}}
+
+	tests := map[int]string{
+		0:   "24",
+		24:  "24",
+		30:  "48",
+		48:  "48",
+		64:  "96",
+		200: "96",
+	}
+	for size, expected := range tests {
+		if url := emoji.BestImage(size).URL; url != expected {
+			t.Errorf("BestImage(%d): expected %s, got %s", size, expected, url)
+		}
+	}
+
+	svg := types.EmojiItem{Thumbnails: []types.Thumbnail{{URL: "svg"}}}
+	if url := svg.BestImage(48).URL; url != "svg" {
+		t.Errorf("Expected the unsized thumbnail, got %s", url)
+	}
+}
+
+func TestParseVoteCount(t *testing.T) {
+	tests := map[string]int{
+		"0 votes":     0,
+		"12 votes":    12,
+		"1,234 votes": 1234,
+		"1.2K votes":  1200,
+		"1,5K votes":  1500,
+		"2,25M votes": 2250000,
+		"3M votes":    3000000,
+		"12 票":        12,
+		"":            0,
+	}
+	for text, expected := range tests {
+		if got := parseVoteCount(text); got != expected {
+			t.Errorf("parseVoteCount(%q) = %d, expected %d", text, got, expected)
+		}
+	}
+}
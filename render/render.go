@@ -0,0 +1,337 @@
+// Package render writes chat items as plain text, HTML, Markdown or ANSI
+// colored terminal output.
+package render
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+// Author name colors YouTube uses for each role
+const (
+	OwnerColor     types.Color = 0xFFFFD600
+	ModeratorColor types.Color = 0xFF5E84F1
+	MemberColor    types.Color = 0xFF2BA640
+)
+
+type options struct {
+	emojiStyle types.EmojiStyle
+	emojiSize  int
+}
+
+// Option configures a renderer
+type Option func(*options)
+
+// WithEmojiStyle selects how emoji are written in text, Markdown and ANSI
+// output. The default writes standard emoji as Unicode and custom ones as
+// :shortcut:.
+func WithEmojiStyle(style types.EmojiStyle) Option {
+	return func(o *options) {
+		o.emojiStyle = style
+	}
+}
+
+// WithEmojiSize sets the pixel size of emoji images in HTML, 24 by default
+func WithEmojiSize(size int) Option {
+	return func(o *options) {
+		o.emojiSize = size
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{emojiStyle: types.EmojiAsUnicode, emojiSize: 24}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// AuthorColor returns the name color of the author's highest role
+func AuthorColor(item types.ChatItem) (types.Color, bool) {
+	roles := item.Author.Roles
+	switch {
+	case roles.IsOwner():
+		return OwnerColor, true
+	case roles.IsModerator():
+		return ModeratorColor, true
+	case roles.IsMember():
+		return MemberColor, true
+	}
+	return 0, false
+}
+
+func roleClass(item types.ChatItem) string {
+	roles := item.Author.Roles
+	switch {
+	case roles.IsOwner():
+		return "owner"
+	case roles.IsModerator():
+		return "moderator"
+	case roles.IsMember():
+		return "member"
+	}
+	return ""
+}
+
+// prefix is "Author" or "Author (amount)", empty for system messages
+func prefix(item types.ChatItem) (author, amount string) {
+	if item.System != nil {
+		return "", ""
+	}
+	if item.SuperChat != nil {
+		amount = item.SuperChat.Amount
+	}
+	return item.Author.Name, amount
+}
+
+// Text renders "Author (amount): message"
+func Text(item types.ChatItem, opts ...Option) string {
+	o := newOptions(opts)
+	message := types.PlainText(item.Message, o.emojiStyle)
+
+	author, amount := prefix(item)
+	if author == "" {
+		return message
+	}
+	if amount != "" {
+		author += " (" + amount + ")"
+	}
+	if message == "" {
+		return author
+	}
+	return author + ": " + message
+}
+
+// HTML renders the item with every text escaped, emoji as <img> with their
+// shortcut as alt text and links restricted to http(s)
+func HTML(item types.ChatItem, opts ...Option) string {
+	var b strings.Builder
+
+	class := "chat-item"
+	if role := roleClass(item); role != "" {
+		class += " " + role
+	}
+	if item.System != nil {
+		class += " system"
+	}
+	fmt.Fprintf(&b, `<div class="%s">`, class)
+
+	author, amount := prefix(item)
+	if author != "" {
+		style := ""
+		if color, ok := AuthorColor(item); ok {
+			style = fmt.Sprintf(` style="color:%s"`, color.Hex())
+		}
+		fmt.Fprintf(&b, `<span class="author"%s>%s</span>`, style, html.EscapeString(author))
+	}
+	if amount != "" {
+		fmt.Fprintf(&b, ` <span class="amount" style="background-color:%s">%s</span>`,
+			item.SuperChat.Colors.BodyBackground.Hex(), html.EscapeString(amount))
+	}
+	if author != "" && len(item.Message) > 0 {
+		b.WriteString(": ")
+	}
+
+	b.WriteString(`<span class="message">`)
+	b.WriteString(HTMLMessage(item.Message, opts...))
+	b.WriteString(`</span></div>`)
+	return b.String()
+}
+
+// HTMLMessage renders message items only, see HTML
+func HTMLMessage(items []types.MessageItem, opts ...Option) string {
+	o := newOptions(opts)
+	var b strings.Builder
+
+	for _, item := range items {
+		if e := item.EmojiItem; e != nil {
+			alt := item.PlainText(types.EmojiAsShortcode)
+			src := e.BestImage(o.emojiSize).URL
+			if !safeURL(src) {
+				b.WriteString(html.EscapeString(alt))
+				continue
+			}
+			fmt.Fprintf(&b, `<img class="emoji" src="%s" alt="%s" title="%s" width="%d" height="%d">`,
+				html.EscapeString(src), html.EscapeString(alt), html.EscapeString(alt), o.emojiSize, o.emojiSize)
+			continue
+		}
+
+		text := html.EscapeString(item.Text)
+		if item.Bold {
+			text = "<b>" + text + "</b>"
+		}
+		if item.Italic {
+			text = "<i>" + text + "</i>"
+		}
+		if item.Strikethrough {
+			text = "<s>" + text + "</s>"
+		}
+
+		switch {
+		case item.Kind == types.RunMention && safeURL(item.URL):
+			fmt.Fprintf(&b, `<a class="mention" href="%s" rel="nofollow noopener" target="_blank">%s</a>`, html.EscapeString(item.URL), text)
+		case item.Kind == types.RunLink && safeURL(item.URL):
+			fmt.Fprintf(&b, `<a href="%s" rel="nofollow noopener" target="_blank">%s</a>`, html.EscapeString(item.URL), text)
+		default:
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+func safeURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, `*`, `\*`, `_`, `\_`, `~`, `\~`, "`", "\\`", `|`, `\|`,
+	`>`, `\>`, `[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`, `#`, `\#`, `@`, "@\u200b",
+)
+
+// Markdown renders Discord flavored Markdown: the author in bold, markup
+// characters escaped, links masked and mentions defused so they never ping
+func Markdown(item types.ChatItem, opts ...Option) string {
+	author, amount := prefix(item)
+	message := MarkdownMessage(item.Message, opts...)
+	if author == "" {
+		return message
+	}
+
+	head := "**" + markdownEscaper.Replace(author) + "**"
+	if amount != "" {
+		head += " (" + markdownEscaper.Replace(amount) + ")"
+	}
+	if message == "" {
+		return head
+	}
+	return head + ": " + message
+}
+
+// MarkdownMessage renders message items only, see Markdown
+func MarkdownMessage(items []types.MessageItem, opts ...Option) string {
+	o := newOptions(opts)
+	var b strings.Builder
+
+	for _, item := range items {
+		if item.EmojiItem != nil {
+			b.WriteString(markdownEscaper.Replace(item.PlainText(o.emojiStyle)))
+			continue
+		}
+
+		text := markdownEscaper.Replace(item.Text)
+		if item.Bold {
+			text = "**" + text + "**"
+		}
+		if item.Italic {
+			text = "*" + text + "*"
+		}
+		if item.Strikethrough {
+			text = "~~" + text + "~~"
+		}
+
+		if (item.Kind == types.RunLink || item.Kind == types.RunMention) && safeURL(item.URL) {
+			// Angle brackets keep Discord from embedding a preview
+			text = "[" + text + "](<" + strings.ReplaceAll(item.URL, ">", "%3E") + ">)"
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+const (
+	ansiReset         = "\x1b[0m"
+	ansiBold          = "\x1b[1m"
+	ansiItalic        = "\x1b[3m"
+	ansiUnderline     = "\x1b[4m"
+	ansiStrikethrough = "\x1b[9m"
+)
+
+func ansiForeground(c types.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
+
+func ansiBackground(c types.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", r, g, b)
+}
+
+// ANSI renders the item for 24-bit color terminals: the author in the role
+// color, Super Chat amounts on their tier color and links underlined
+func ANSI(item types.ChatItem, opts ...Option) string {
+	author, amount := prefix(item)
+	message := ANSIMessage(item.Message, opts...)
+	if author == "" {
+		return message
+	}
+
+	author = stripControl(author)
+	head := ansiBold + author + ansiReset
+	if color, ok := AuthorColor(item); ok {
+		head = ansiBold + ansiForeground(color) + author + ansiReset
+	}
+	if amount != "" {
+		sc := item.SuperChat
+		background, text := sc.Colors.BodyBackground, sc.Colors.BodyText
+		if sc.Sticker != nil || text == 0 {
+			// Super Stickers only color their money chip
+			background, text = sc.Colors.MoneyChipBackground, sc.Colors.MoneyChipText
+		}
+		head += " " + ansiBackground(background) + ansiForeground(text) + " " + stripControl(amount) + " " + ansiReset
+	}
+	if message == "" {
+		return head
+	}
+	return head + ": " + message
+}
+
+// ANSIMessage renders message items only, see ANSI
+func ANSIMessage(items []types.MessageItem, opts ...Option) string {
+	o := newOptions(opts)
+	var b strings.Builder
+
+	for _, item := range items {
+		if item.EmojiItem != nil {
+			b.WriteString(stripControl(item.PlainText(o.emojiStyle)))
+			continue
+		}
+
+		style := ""
+		if item.Bold {
+			style += ansiBold
+		}
+		if item.Italic {
+			style += ansiItalic
+		}
+		if item.Strikethrough {
+			style += ansiStrikethrough
+		}
+		if item.Kind == types.RunLink || item.Kind == types.RunMention {
+			style += ansiUnderline
+		}
+
+		text := stripControl(item.Text)
+
+		if style == "" {
+			b.WriteString(text)
+		} else {
+			b.WriteString(style + text + ansiReset)
+		}
+	}
+	return b.String()
+}
+
+// stripControl keeps escape sequences sent in chat from reaching the terminal
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,170 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+var clap = types.MessageItem{
+	Kind: types.RunEmoji,
+	EmojiItem: &types.EmojiItem{
+		ImageItem: types.ImageItem{URL: "https://emoji.url/clap.svg", Alt: ":clapping_hands:"},
+		EmojiText: "👏",
+		Unicode:   "👏",
+		Shortcuts: []string{":clapping_hands:", ":clap:"},
+	},
+}
+
+var custom = types.MessageItem{
+	Kind: types.RunEmoji,
+	EmojiItem: &types.EmojiItem{
+		ImageItem:     types.ImageItem{URL: "https://custom.emoji.url", Alt: ":customEmoji:"},
+		EmojiText:     ":customEmoji:",
+		IsCustomEmoji: true,
+		Shortcuts:     []string{":customEmoji:"},
+		Thumbnails: []types.Thumbnail{
+			{URL: "https://custom.emoji.url/24", Width: 24, Height: 24},
+			{URL: "https://custom.emoji.url/48", Width: 48, Height: 48},
+		},
+	},
+}
+
+func chatItem(message ...types.MessageItem) types.ChatItem {
+	return types.ChatItem{
+		ID:      "id",
+		Author:  types.Author{Name: "authorName"},
+		Message: message,
+	}
+}
+
+func TestText(t *testing.T) {
+	superChat := chatItem(types.MessageItem{Text: "thanks"})
+	superChat.SuperChat = &types.SuperChat{Amount: "￥1,000"}
+
+	system := chatItem(types.MessageItem{Text: "Slow mode is on"})
+	system.System = &types.SystemMessage{Kind: types.SystemSlowMode}
+
+	tests := []struct {
+		name     string
+		item     types.ChatItem
+		opts     []Option
+		expected string
+	}{
+		{"Text", chatItem(types.MessageItem{Text: "hello"}), nil, "authorName: hello"},
+		{"Emoji", chatItem(types.MessageItem{Text: "nice "}, clap, custom), nil, "authorName: nice 👏:customEmoji:"},
+		{"Shortcodes", chatItem(clap), []Option{WithEmojiStyle(types.EmojiAsShortcode)}, "authorName: :clapping_hands:"},
+		{"Super Chat", superChat, nil, "authorName (￥1,000): thanks"},
+		{"Sticker only", func() types.ChatItem { i := superChat; i.Message = nil; return i }(), nil, "authorName (￥1,000)"},
+		{"System", system, nil, "Slow mode is on"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if text := Text(tt.item, tt.opts...); text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	item := chatItem(
+		types.MessageItem{Text: "<script>alert(1)</script> "},
+		custom,
+		types.MessageItem{Text: " "},
+		types.MessageItem{Text: "@streamer", Kind: types.RunMention, URL: "https://www.youtube.com/@streamer", ChannelID: "UCstreamer"},
+		types.MessageItem{Text: " "},
+		types.MessageItem{Text: "bad", Kind: types.RunLink, URL: "javascript:alert(1)"},
+		types.MessageItem{Text: " "},
+		types.MessageItem{Text: "bold", Bold: true},
+	)
+	item.Author.Name = `<b>evil</b>`
+	item.Author.Roles = types.RoleModerator
+
+	out := HTML(item, WithEmojiSize(48))
+
+	for _, expected := range []string{
+		`<div class="chat-item moderator">`,
+		`<span class="author" style="color:#5E84F1">&lt;b&gt;evil&lt;/b&gt;</span>`,
+		`&lt;script&gt;alert(1)&lt;/script&gt;`,
+		`<img class="emoji" src="https://custom.emoji.url/48" alt=":customEmoji:" title=":customEmoji:" width="48" height="48">`,
+		`<a class="mention" href="https://www.youtube.com/@streamer" rel="nofollow noopener" target="_blank">@streamer</a>`,
+		`<b>bold</b>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %s in %s", expected, out)
+		}
+	}
+	if strings.Contains(out, "javascript:") || strings.Contains(out, "<script>") {
+		t.Errorf("Unsafe output %s", out)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	item := chatItem(
+		types.MessageItem{Text: "*not bold* @everyone "},
+		types.MessageItem{Text: "link", Kind: types.RunLink, URL: "https://example.com/page"},
+		types.MessageItem{Text: " "},
+		types.MessageItem{Text: "strong", Bold: true},
+		types.MessageItem{Text: " "},
+		clap,
+	)
+	item.Author.Name = "author_name"
+
+	expected := "**author\\_name**: \\*not bold\\* @\u200beveryone [link](<https://example.com/page>) **strong** 👏"
+	if out := Markdown(item); out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+}
+
+func TestANSI(t *testing.T) {
+	item := chatItem(
+		types.MessageItem{Text: "hi\x1b[2J "},
+		types.MessageItem{Text: "link", Kind: types.RunLink, URL: "https://example.com"},
+	)
+	item.Author.Roles = types.RoleOwner
+	item.SuperChat = &types.SuperChat{
+		Amount: "$5.00",
+		Colors: types.SuperChatColors{BodyBackground: 0xFF1DE9B6, BodyText: 0xDF000000},
+	}
+
+	expected := "\x1b[1m\x1b[38;2;255;214;0mauthorName\x1b[0m" +
+		" \x1b[48;2;29;233;182m\x1b[38;2;0;0;0m $5.00 \x1b[0m" +
+		": hi[2J \x1b[4mlink\x1b[0m"
+	if out := ANSI(item); out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	sticker := chatItem()
+	sticker.SuperChat = &types.SuperChat{
+		Amount:  "$2.00",
+		Sticker: &types.ImageItem{URL: "https://sticker.url"},
+		Colors:  types.SuperChatColors{BodyBackground: 0xFF1565C0, MoneyChipBackground: 0xFF1E88E5, MoneyChipText: 0xFFFFFFFF},
+	}
+	expected = "\x1b[1mauthorName\x1b[0m \x1b[48;2;30;136;229m\x1b[38;2;255;255;255m $2.00 \x1b[0m"
+	if out := ANSI(sticker); out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	escape := custom
+	emoji := *custom.EmojiItem
+	emoji.Shortcuts = []string{":custom\x1b[2JEmoji:"}
+	escape.EmojiItem = &emoji
+	if out := ANSIMessage([]types.MessageItem{escape}); out != ":custom[2JEmoji:" {
+		t.Errorf("Expected control characters stripped from emoji, got %q", out)
+	}
+}
+
+func TestAuthorColor(t *testing.T) {
+	item := chatItem()
+	if _, ok := AuthorColor(item); ok {
+		t.Error("Expected no color for a viewer")
+	}
+
+	item.Author.Roles = types.RoleMember | types.RoleModerator
+	if color, _ := AuthorColor(item); color != ModeratorColor {
+		t.Errorf("Expected moderator color to win, got %s", color.Hex())
+	}
+}
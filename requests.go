@@ -3,9 +3,11 @@ package youtubechat
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/DiegPS/youtube-chat/types"
@@ -14,11 +16,28 @@ import (
 var (
 	clientName     = "WEB"
 	BaseURL        = "https://www.youtube.com/youtubei/v1/live_chat/get_live_chat"
+	NextURL        = "https://www.youtube.com/youtubei/v1/next"
 	YoutubeBaseURL = "https://www.youtube.com"
+
+	// DefaultClientVersion is sent when the client version is not taken from a page
+	DefaultClientVersion = "2.20240101.00.00"
 )
 
 func FetchChat(options types.FetchOptions) ([]types.ChatItem, string, error) {
-	url := fmt.Sprintf("%s?key=%s", BaseURL, options.ApiKey)
+	actions, continuation, err := FetchChatActions(options)
+	return actions.Items, continuation, err
+}
+
+// FetchChatActions is FetchChat including events other than new chat items
+func FetchChatActions(options types.FetchOptions) (types.ChatActions, string, error) {
+	return fetchChatActions(options)
+}
+
+func fetchChatActions(options types.FetchOptions, opts ...ParseOption) (types.ChatActions, string, error) {
+	url := BaseURL
+	if options.ApiKey != "" {
+		url = fmt.Sprintf("%s?key=%s", BaseURL, options.ApiKey)
+	}
 
 	payload := map[string]interface{}{
 		"context": map[string]interface{}{
@@ -32,33 +51,103 @@ func FetchChat(options types.FetchOptions) ([]types.ChatItem, string, error) {
 
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, "", err
+		return types.ChatActions{}, "", err
 	}
 
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
 	if err != nil {
-		return nil, "", err
+		return types.ChatActions{}, "", err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "utf-8")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, "", err
+		return types.ChatActions{}, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return types.ChatActions{}, "", fmt.Errorf("failed to fetch chat: status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return types.ChatActions{}, "", err
+	}
+
+	return ParseChatResponse(body, opts...)
+}
+
+// FetchNext bootstraps chat options from the innertube next endpoint
+// instead of downloading the watch page. It requires a LiveID.
+func FetchNext(id types.YoutubeId) (types.FetchOptions, error) {
+	if id.LiveID == "" {
+		return types.FetchOptions{}, fmt.Errorf("next requires a liveId")
+	}
+
+	payload := map[string]interface{}{
+		"context": map[string]interface{}{
+			"client": map[string]string{
+				"clientVersion": DefaultClientVersion,
+				"clientName":    clientName,
+			},
+		},
+		"videoId": id.LiveID,
+	}
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return types.FetchOptions{}, err
+	}
+
+	req, err := http.NewRequest("POST", NextURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return types.FetchOptions{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return types.FetchOptions{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("failed to fetch chat: status %d", resp.StatusCode)
+		return types.FetchOptions{}, fmt.Errorf("failed to fetch next: status %d", resp.StatusCode)
 	}
 
-	var parsedResponse types.GetLiveChatResponse
+	var parsedResponse types.NextResponse
 	if err := json.NewDecoder(resp.Body).Decode(&parsedResponse); err != nil {
-		return nil, "", err
+		return types.FetchOptions{}, err
+	}
+
+	opts, err := GetOptionsFromNextResponse(parsedResponse)
+	if err != nil {
+		return opts, err
 	}
+	opts.ClientVersion = DefaultClientVersion
 
-	items, continuation := ParseChatData(parsedResponse)
-	return items, continuation, nil
+	return opts, nil
+}
+
+// FetchInnertubeOptions bootstraps with FetchNext and falls back to FetchLivePage
+// when id has no LiveID or the next endpoint does not give a usable answer.
+func FetchInnertubeOptions(id types.YoutubeId) (types.FetchOptions, error) {
+	if id.LiveID != "" {
+		opts, err := FetchNext(id)
+		if err == nil {
+			return opts, nil
+		}
+
+		// The stream state is authoritative, no need to ask the page again
+		var stateErr *PageStateError
+		if errors.As(err, &stateErr) && stateErr.State != PageStateNotLive {
+			return opts, err
+		}
+	}
+
+	return FetchLivePage(id)
 }
 
 func FetchLivePage(id types.YoutubeId) (types.FetchOptions, error) {
@@ -67,30 +156,72 @@ func FetchLivePage(id types.YoutubeId) (types.FetchOptions, error) {
 		return types.FetchOptions{}, fmt.Errorf("id not found")
 	}
 
+	data, err := fetchPage(url)
+	if err != nil {
+		return types.FetchOptions{}, fmt.Errorf("failed to fetch live page: %w", err)
+	}
+
+	return GetOptionsFromLivePage(data)
+}
+
+// FetchLiveChatPage downloads the live_chat popout page for the options' continuation
+// and returns the chat backlog it embeds together with the continuation following it.
+func FetchLiveChatPage(options types.FetchOptions) (types.ChatActions, string, error) {
+	return fetchLiveChatPage(options)
+}
+
+func fetchLiveChatPage(options types.FetchOptions, opts ...ParseOption) (types.ChatActions, string, error) {
+	query := url.Values{"continuation": {options.Continuation}}.Encode()
+
+	data, err := fetchPage(YoutubeBaseURL + "/live_chat?" + query)
+	if err != nil {
+		return types.ChatActions{}, "", fmt.Errorf("failed to fetch live chat page: %w", err)
+	}
+
+	return ParseLiveChatPage(data, opts...)
+}
+
+// ResolveChannel fetches the page for id and returns its owning channel,
+// turning handles, custom URLs and video IDs into a stable channel ID.
+func ResolveChannel(id types.YoutubeId) (types.Channel, error) {
+	url := generateChannelUrl(id)
+	if url == "" {
+		return types.Channel{}, fmt.Errorf("id not found")
+	}
+
+	data, err := fetchPage(url)
+	if err != nil {
+		return types.Channel{}, fmt.Errorf("failed to fetch channel page: %w", err)
+	}
+
+	return GetChannelFromPage(data)
+}
+
+func fetchPage(url string) (string, error) {
 	// Axios user-agent mimicry might be needed? Usually YouTube needs a User-Agent.
 	client := &http.Client{}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return types.FetchOptions{}, err
+		return "", err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return types.FetchOptions{}, err
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return types.FetchOptions{}, fmt.Errorf("failed to fetch live page: status %d", resp.StatusCode)
+		return "", fmt.Errorf("status %d", resp.StatusCode)
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return types.FetchOptions{}, err
+		return "", err
 	}
 
-	return GetOptionsFromLivePage(string(bodyBytes))
+	return string(bodyBytes), nil
 }
 
 func generateLiveUrl(id types.YoutubeId) string {
@@ -104,6 +235,25 @@ func generateLiveUrl(id types.YoutubeId) string {
 			handle = "@" + handle
 		}
 		return fmt.Sprintf("%s/%s/live", YoutubeBaseURL, handle)
+	} else if id.CustomURL != "" {
+		return fmt.Sprintf("%s/c/%s/live", YoutubeBaseURL, id.CustomURL)
+	}
+	return ""
+}
+
+func generateChannelUrl(id types.YoutubeId) string {
+	if id.ChannelID != "" {
+		return fmt.Sprintf("%s/channel/%s", YoutubeBaseURL, id.ChannelID)
+	} else if id.LiveID != "" {
+		return fmt.Sprintf("%s/watch?v=%s", YoutubeBaseURL, id.LiveID)
+	} else if id.Handle != "" {
+		handle := id.Handle
+		if !strings.HasPrefix(handle, "@") {
+			handle = "@" + handle
+		}
+		return fmt.Sprintf("%s/%s", YoutubeBaseURL, handle)
+	} else if id.CustomURL != "" {
+		return fmt.Sprintf("%s/c/%s", YoutubeBaseURL, id.CustomURL)
 	}
 	return ""
 }
@@ -3,8 +3,10 @@ package youtubechat
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/DiegPS/youtube-chat/types"
@@ -139,4 +141,133 @@ func TestFetchLivePage_Detailed(t *testing.T) {
 
 		FetchLivePage(types.YoutubeId{Handle: "handle"})
 	})
+
+	t.Run("CustomURL request", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/c/custom/live" {
+				t.Errorf("Expected path /c/custom/live, got %s", r.URL.Path)
+			}
+		}))
+		defer ts.Close()
+		YoutubeBaseURL = ts.URL
+
+		FetchLivePage(types.YoutubeId{CustomURL: "custom"})
+	})
+}
+
+func TestResolveChannel(t *testing.T) {
+	origYoutubeBaseURL := YoutubeBaseURL
+	defer func() { YoutubeBaseURL = origYoutubeBaseURL }()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/@handle" {
+			t.Errorf("Expected path /@handle, got %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `<meta itemprop="channelId" content="UCxkOLgdNumvVIQqn5ps_bJA">`+
+			`"channelMetadataRenderer":{"title":"title","vanityChannelUrl":"http://www.youtube.com/@handle"}`)
+	}))
+	defer ts.Close()
+	YoutubeBaseURL = ts.URL
+
+	channel, err := ResolveChannel(types.YoutubeId{Handle: "handle"})
+	if err != nil {
+		t.Fatalf("ResolveChannel failed: %v", err)
+	}
+
+	expected := types.Channel{ID: "UCxkOLgdNumvVIQqn5ps_bJA", Handle: "@handle", Title: "title"}
+	if channel != expected {
+		t.Errorf("Expected %+v, got %+v", expected, channel)
+	}
+}
+
+func TestFetchNext(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "next.live.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected method POST, got %s", r.Method)
+		}
+
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["videoId"] != "liveId" {
+			t.Errorf("Expected videoId 'liveId', got %v", payload["videoId"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	origNextURL := NextURL
+	NextURL = ts.URL
+	defer func() { NextURL = origNextURL }()
+
+	opts, err := FetchNext(types.YoutubeId{LiveID: "liveId"})
+	if err != nil {
+		t.Fatalf("FetchNext failed: %v", err)
+	}
+	if opts.Continuation != "test-continuation:top" {
+		t.Errorf("Expected continuation test-continuation:top, got %s", opts.Continuation)
+	}
+	if opts.ClientVersion != DefaultClientVersion {
+		t.Errorf("Expected client version %s, got %s", DefaultClientVersion, opts.ClientVersion)
+	}
+}
+
+func TestFetchInnertubeOptions_Fallback(t *testing.T) {
+	next := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer next.Close()
+
+	pageRequested := false
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageRequested = true
+		if r.URL.Path != "/watch" || r.URL.Query().Get("v") != "liveId" {
+			t.Errorf("Expected path /watch?v=liveId, got %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+	}))
+	defer page.Close()
+
+	origNextURL, origYoutubeBaseURL := NextURL, YoutubeBaseURL
+	NextURL, YoutubeBaseURL = next.URL, page.URL
+	defer func() { NextURL, YoutubeBaseURL = origNextURL, origYoutubeBaseURL }()
+
+	FetchInnertubeOptions(types.YoutubeId{LiveID: "liveId"})
+	if !pageRequested {
+		t.Error("Expected fallback to the live page")
+	}
+}
+
+func TestFetchLiveChatPage(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "live_chat_page.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/live_chat" || r.URL.Query().Get("continuation") != "0ofMy+A%3D=" {
+			t.Errorf("Expected path /live_chat with the continuation escaped, got %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	origYoutubeBaseURL := YoutubeBaseURL
+	YoutubeBaseURL = ts.URL
+	defer func() { YoutubeBaseURL = origYoutubeBaseURL }()
+
+	options := mockOptions
+	options.Continuation = "0ofMy+A%3D="
+	actions, continuation, err := FetchLiveChatPage(options)
+	if err != nil {
+		t.Fatalf("FetchLiveChatPage failed: %v", err)
+	}
+	if len(actions.Items) != 2 || continuation != "test-continuation:backlog" {
+		t.Errorf("Unexpected backlog: %d items, continuation %s", len(actions.Items), continuation)
+	}
 }
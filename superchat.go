@@ -0,0 +1,92 @@
+package youtubechat
+
+import (
+	"time"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+// superChatTiers lists YouTube's Super Chat bands from the cheapest. The body
+// background identifies the band whatever the currency; the duration is how
+// long it stays pinned in the ticker.
+var superChatTiers = []struct {
+	tier     types.SuperChatTier
+	body     types.Color
+	header   types.Color
+	sticker  types.Color // Super Sticker money chip
+	duration time.Duration
+}{
+	{types.SuperChatTierBlue, 0xFF1565C0, 0xFF1565C0, 0xFF1E88E5, 0},
+	{types.SuperChatTierCyan, 0xFF00E5FF, 0xFF00B8D4, 0xFF00B8D4, 0},
+	{types.SuperChatTierGreen, 0xFF1DE9B6, 0xFF00BFA5, 0xFF00BFA5, 2 * time.Minute},
+	{types.SuperChatTierYellow, 0xFFFFCA28, 0xFFFFB300, 0xFFFFB300, 5 * time.Minute},
+	{types.SuperChatTierOrange, 0xFFF57C00, 0xFFE65100, 0xFFE65100, 10 * time.Minute},
+	{types.SuperChatTierMagenta, 0xFFE91E63, 0xFFC2185B, 0xFFC2185B, 30 * time.Minute},
+	{types.SuperChatTierRed, 0xFFE62117, 0xFFD00000, 0xFFD00000, time.Hour},
+}
+
+func parseSuperChat(amount string, colors types.SuperChatColors) *types.SuperChat {
+	money, _ := ParseMoney(amount)
+	tier := superChatTier(colors)
+
+	return &types.SuperChat{
+		Amount:   amount,
+		Money:    money,
+		Color:    colors.BodyBackground.Hex(),
+		Colors:   colors,
+		Tier:     tier,
+		Duration: superChatDuration(tier, money),
+	}
+}
+
+// superChatTier matches the money chip of a Super Sticker, then the body or
+// header color, to a band. Unlisted colors get the nearest body color.
+func superChatTier(colors types.SuperChatColors) types.SuperChatTier {
+	if colors.MoneyChipBackground != 0 {
+		for _, t := range superChatTiers {
+			if colors.MoneyChipBackground == t.sticker {
+				return t.tier
+			}
+		}
+	}
+	for _, t := range superChatTiers {
+		if colors.BodyBackground == t.body || colors.HeaderBackground == t.header {
+			return t.tier
+		}
+	}
+
+	if colors.BodyBackground == 0 {
+		return types.SuperChatTierUnknown
+	}
+	best, bestDistance := types.SuperChatTierUnknown, -1
+	for _, t := range superChatTiers {
+		if d := colorDistance(colors.BodyBackground, t.body); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = t.tier, d
+		}
+	}
+	return best
+}
+
+// superChatDuration is the band's ticker time. Red ones stay one more hour per
+// extra 100 USD, up to 5 hours; other currencies get the one hour minimum.
+func superChatDuration(tier types.SuperChatTier, money types.Money) time.Duration {
+	var duration time.Duration
+	for _, t := range superChatTiers {
+		if t.tier == tier {
+			duration = t.duration
+		}
+	}
+
+	if tier == types.SuperChatTierRed && money.Currency == "USD" {
+		hours := min(max(money.Micros/100000000, 1), 5)
+		duration = time.Duration(hours) * time.Hour
+	}
+	return duration
+}
+
+func colorDistance(a, b types.Color) int {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	dr, dg, db := int(ar)-int(br), int(ag)-int(bg), int(ab)-int(bb)
+	return dr*dr + dg*dg + db*db
+}
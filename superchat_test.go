@@ -0,0 +1,73 @@
+package youtubechat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+func TestSuperChatTier(t *testing.T) {
+	tests := []struct {
+		name     string
+		colors   types.SuperChatColors
+		expected types.SuperChatTier
+	}{
+		{"Blue", types.SuperChatColors{BodyBackground: 0xFF1565C0}, types.SuperChatTierBlue},
+		{"Cyan", types.SuperChatColors{BodyBackground: 0xFF00E5FF}, types.SuperChatTierCyan},
+		{"Green", types.SuperChatColors{BodyBackground: 0xFF1DE9B6}, types.SuperChatTierGreen},
+		{"Yellow", types.SuperChatColors{BodyBackground: 0xFFFFCA28}, types.SuperChatTierYellow},
+		{"Orange", types.SuperChatColors{BodyBackground: 0xFFF57C00}, types.SuperChatTierOrange},
+		{"Magenta", types.SuperChatColors{BodyBackground: 0xFFE91E63}, types.SuperChatTierMagenta},
+		{"Red", types.SuperChatColors{BodyBackground: 0xFFE62117}, types.SuperChatTierRed},
+		{"Header only", types.SuperChatColors{HeaderBackground: 0xFFE65100}, types.SuperChatTierOrange},
+		{"Sticker chip", types.SuperChatColors{BodyBackground: 0xFF000000, MoneyChipBackground: 0xFF00BFA5}, types.SuperChatTierGreen},
+		{"Sticker chip first", types.SuperChatColors{BodyBackground: 0xFF1565C0, MoneyChipBackground: 0xFF00BFA5}, types.SuperChatTierGreen},
+		{"Nearest", types.SuperChatColors{BodyBackground: 0xFFE62010}, types.SuperChatTierRed},
+		{"None", types.SuperChatColors{}, types.SuperChatTierUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tier := superChatTier(tt.colors); tier != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, tier)
+			}
+		})
+	}
+}
+
+func TestSuperChatDuration(t *testing.T) {
+	tests := []struct {
+		tier     types.SuperChatTier
+		amount   string
+		expected time.Duration
+	}{
+		{types.SuperChatTierBlue, "$1.00", 0},
+		{types.SuperChatTierGreen, "$5.00", 2 * time.Minute},
+		{types.SuperChatTierMagenta, "$50.00", 30 * time.Minute},
+		{types.SuperChatTierRed, "$100.00", time.Hour},
+		{types.SuperChatTierRed, "$250.00", 2 * time.Hour},
+		{types.SuperChatTierRed, "$500.00", 5 * time.Hour},
+		{types.SuperChatTierRed, "$1,000.00", 5 * time.Hour},
+		{types.SuperChatTierRed, "￥50,000", time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			money, _ := ParseMoney(tt.amount)
+			if d := superChatDuration(tt.tier, money); d != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestColor(t *testing.T) {
+	c := types.Color(0xDF112233)
+	if c.Hex() != "#112233" {
+		t.Errorf("Expected #112233, got %s", c.Hex())
+	}
+	if c.HexAlpha() != "#112233DF" {
+		t.Errorf("Expected #112233DF, got %s", c.HexAlpha())
+	}
+}
@@ -1,6 +1,11 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ChatItem represents the detailed chat info
 type ChatItem struct {
@@ -8,29 +13,577 @@ type ChatItem struct {
 	Author       Author
 	Message      []MessageItem
 	SuperChat    *SuperChat
+	Gift         *Gift
+	Membership   *Membership
+	System       *SystemMessage // set on items only found in ChatActions.System and on EventChan
 	IsMembership bool
 	IsVerified   bool
 	IsOwner      bool
 	IsModerator  bool
 	Timestamp    time.Time
+	IsBacklog    bool            // sent before observation started, from the initial page load
+	Raw          json.RawMessage // the action, kept with LiveChat's WithRawActions
+
+	// IsTimestampSynthesized is set when timestampUsec was missing or invalid
+	// and Timestamp is the time the item was parsed instead
+	IsTimestampSynthesized bool
+	// ReceivedAt is when LiveChat fetched the item, zero from the parser
+	ReceivedAt time.Time
+	// Offset is the time since the stream started, read from
+	// videoOffsetTimeMsec in replays or computed from FetchOptions.StartTime.
+	// Negative for messages sent in the waiting room.
+	Offset    time.Duration
+	HasOffset bool
+}
+
+// Latency is the time between the message being sent and received, zero
+// when either is unknown
+func (c ChatItem) Latency() time.Duration {
+	if c.ReceivedAt.IsZero() || c.Timestamp.IsZero() || c.IsTimestampSynthesized {
+		return 0
+	}
+	return c.ReceivedAt.Sub(c.Timestamp)
+}
+
+// ChatActions groups everything decoded from one batch of chat actions
+type ChatActions struct {
+	Items        []ChatItem
+	System       []ChatItem // author-less items posted by YouTube, with System set
+	Deletions    []Deletion
+	Replacements []Replacement
+	Tickers      []Ticker
+	Polls        []PollEvent
+	Banners      []BannerEvent
+	Redirects    []Redirect
+	Unhandled    []UnhandledAction // actions none of the above was decoded from
+
+	order []eventRef // set by Add
+}
+
+// Event is a ChatItem, Deletion, Replacement, Ticker, PollEvent, BannerEvent,
+// Redirect, CustomEvent or UnhandledAction. UnhandledAction only appears in
+// ChatActions.Events and Unhandled: LiveChat never sends it on its channels but
+// hands it to a registered parser, whose CustomEvent it sends, or to Diagnostics.
+type Event interface {
+	event()
+}
+
+func (ChatItem) event()        {}
+func (Deletion) event()        {}
+func (Replacement) event()     {}
+func (Ticker) event()          {}
+func (PollEvent) event()       {}
+func (BannerEvent) event()     {}
+func (Redirect) event()        {}
+func (CustomEvent) event()     {}
+func (UnhandledAction) event() {}
+
+type eventKind uint8
+
+const (
+	eventItem eventKind = iota
+	eventSystem
+	eventDeletion
+	eventReplacement
+	eventTicker
+	eventPoll
+	eventBanner
+	eventRedirect
+	eventUnhandled
+)
+
+type eventRef struct {
+	kind  eventKind
+	index int
+}
+
+// Add appends the event to the slice of its type, remembering its position
+// among all events. CustomEvent has no slice and is ignored.
+func (a *ChatActions) Add(event Event) {
+	ref := eventRef{}
+	switch e := event.(type) {
+	case ChatItem:
+		if e.System != nil {
+			ref = eventRef{eventSystem, len(a.System)}
+			a.System = append(a.System, e)
+			break
+		}
+		ref = eventRef{eventItem, len(a.Items)}
+		a.Items = append(a.Items, e)
+	case Deletion:
+		ref = eventRef{eventDeletion, len(a.Deletions)}
+		a.Deletions = append(a.Deletions, e)
+	case Replacement:
+		ref = eventRef{eventReplacement, len(a.Replacements)}
+		a.Replacements = append(a.Replacements, e)
+	case Ticker:
+		ref = eventRef{eventTicker, len(a.Tickers)}
+		a.Tickers = append(a.Tickers, e)
+	case PollEvent:
+		ref = eventRef{eventPoll, len(a.Polls)}
+		a.Polls = append(a.Polls, e)
+	case BannerEvent:
+		ref = eventRef{eventBanner, len(a.Banners)}
+		a.Banners = append(a.Banners, e)
+	case Redirect:
+		ref = eventRef{eventRedirect, len(a.Redirects)}
+		a.Redirects = append(a.Redirects, e)
+	case UnhandledAction:
+		ref = eventRef{eventUnhandled, len(a.Unhandled)}
+		a.Unhandled = append(a.Unhandled, e)
+	default:
+		return
+	}
+	a.order = append(a.order, ref)
+}
+
+// Events returns every event in the order of the actions they were decoded
+// from. Events that were not added with Add, e.g. when ChatActions is built by
+// hand, follow type by type.
+func (a ChatActions) Events() []Event {
+	counts := map[eventKind]int{}
+	var events []Event
+	for _, ref := range a.order {
+		if e := a.event(ref); e != nil {
+			events = append(events, e)
+			counts[ref.kind]++
+		}
+	}
+
+	lengths := []int{len(a.Items), len(a.System), len(a.Deletions), len(a.Replacements), len(a.Tickers),
+		len(a.Polls), len(a.Banners), len(a.Redirects), len(a.Unhandled)}
+	for kind, n := range lengths {
+		for i := counts[eventKind(kind)]; i < n; i++ {
+			events = append(events, a.event(eventRef{eventKind(kind), i}))
+		}
+	}
+	return events
+}
+
+func (a ChatActions) event(ref eventRef) Event {
+	i := ref.index
+	switch ref.kind {
+	case eventItem:
+		if i < len(a.Items) {
+			return a.Items[i]
+		}
+	case eventSystem:
+		if i < len(a.System) {
+			return a.System[i]
+		}
+	case eventDeletion:
+		if i < len(a.Deletions) {
+			return a.Deletions[i]
+		}
+	case eventReplacement:
+		if i < len(a.Replacements) {
+			return a.Replacements[i]
+		}
+	case eventTicker:
+		if i < len(a.Tickers) {
+			return a.Tickers[i]
+		}
+	case eventPoll:
+		if i < len(a.Polls) {
+			return a.Polls[i]
+		}
+	case eventBanner:
+		if i < len(a.Banners) {
+			return a.Banners[i]
+		}
+	case eventRedirect:
+		if i < len(a.Redirects) {
+			return a.Redirects[i]
+		}
+	case eventUnhandled:
+		if i < len(a.Unhandled) {
+			return a.Unhandled[i]
+		}
+	}
+	return nil
+}
+
+// UnhandledAction is an action the parser did not recognize, with the
+// renderer it carries when it adds or replaces an item
+type UnhandledAction struct {
+	Key         string          // action key, e.g. "addChatItemAction"
+	RendererKey string          // e.g. "liveChatFooRenderer", empty for other actions
+	Renderer    json.RawMessage // the value under RendererKey, or under Key
+	Raw         json.RawMessage // the whole action
+}
+
+// CustomEvent is produced by a parser registered on LiveChat for an
+// action or renderer key
+type CustomEvent struct {
+	Key   string // the key the parser was registered for
+	Value any
+	Raw   json.RawMessage
+}
+
+// Deletion is a moderator removing a single chat item or every item of an author
+type Deletion struct {
+	TargetItemID    string        // set when a single item was deleted
+	AuthorChannelID string        // set when every item of the author was deleted
+	Message         []MessageItem // e.g. "[message deleted]"
+	Raw             json.RawMessage
+}
+
+// Replacement swaps the chat item TargetItemID for Item,
+// e.g. a placeholder for its real content
+type Replacement struct {
+	TargetItemID string
+	Item         ChatItem
+	Raw          json.RawMessage
+}
+
+// TickerKind identifies what a ticker item advertises
+type TickerKind int
+
+const (
+	TickerPaidMessage TickerKind = iota + 1
+	TickerPaidSticker
+	TickerMembership
+	TickerGift
+)
+
+// Ticker is an item of the pinned strip at the top of the chat
+type Ticker struct {
+	ID                   string
+	Kind                 TickerKind
+	Author               Author
+	Amount               string     // paid amount, or the membership detail text
+	Sticker              *ImageItem // set for TickerPaidSticker
+	AmountTextColor      string
+	StartBackgroundColor string
+	EndBackgroundColor   string
+	Duration             time.Duration // remaining time on the strip
+	FullDuration         time.Duration
+	LinkedItemID         string    // ID of the chat item the ticker opens
+	LinkedItem           *ChatItem // the item itself, when it could be parsed
+	Raw                  json.RawMessage
+}
+
+// PollState tells whether a poll still accepts votes
+type PollState int
+
+const (
+	PollOpen PollState = iota + 1
+	PollClosed
+)
+
+// Poll is a poll created by the streamer
+type Poll struct {
+	ID         string
+	PanelID    string // action panel showing the poll, used when it is closed
+	Question   []MessageItem
+	Author     Author // name and thumbnail of the creator, when known
+	Choices    []PollChoice
+	TotalVotes int
+	State      PollState
+}
+
+type PollChoice struct {
+	Text           []MessageItem
+	VotePercentage string  // as displayed, e.g. "45%"
+	VoteRatio      float64 // 0 to 1
+	Selected       bool
+}
+
+// PollEventKind is the step of a poll's lifecycle
+type PollEventKind int
+
+const (
+	PollCreated PollEventKind = iota + 1
+	PollUpdated
+	PollEnded
+)
+
+type PollEvent struct {
+	Kind PollEventKind
+	Poll Poll
+	Raw  json.RawMessage
+}
+
+// BannerKind identifies what a banner shows
+type BannerKind int
+
+const (
+	BannerUnknown BannerKind = iota
+	BannerPinnedMessage
+	BannerChatSummary
+	BannerRedirect
+)
+
+// Banner is shown above the chat, e.g. a pinned message
+type Banner struct {
+	ID        string
+	Kind      BannerKind
+	Header    []MessageItem // e.g. "Pinned by authorName"
+	Item      *ChatItem     // set for BannerPinnedMessage
+	Text      []MessageItem // chat summary or redirect message
+	Thumbnail *ImageItem    // redirect author photo
+}
+
+// BannerEventKind tells whether a banner was added or removed
+type BannerEventKind int
+
+const (
+	BannerAdded BannerEventKind = iota + 1
+	BannerRemoved
+)
+
+// BannerEvent carries the full Banner when added and only its ID when removed
+type BannerEvent struct {
+	Kind   BannerEventKind
+	Banner Banner
+	Raw    json.RawMessage
 }
 
 type Author struct {
 	Name      string
 	Thumbnail *ImageItem
 	ChannelID string
-	Badge     *Badge
+	Badge     *Badge  // the membership badge, also listed in Badges
+	Badges    []Badge // every badge, in display order
+	Roles     Role
 }
 
+// BadgeType tells what an author badge stands for
+type BadgeType int
+
+const (
+	BadgeUnknown BadgeType = iota
+	BadgeOwner
+	BadgeModerator
+	BadgeVerified
+	BadgeMember
+	BadgeArtist
+)
+
 type Badge struct {
-	Thumbnail ImageItem
-	Label     string
+	Thumbnail  ImageItem // largest thumbnail of custom badges
+	Label      string    // tooltip, e.g. "Member (2 years)"
+	Type       BadgeType
+	Icon       string      // YouTube icon type of built-in badges, e.g. "MODERATOR"
+	Thumbnails []Thumbnail // every size of custom badges
+	Tier       string      // BadgeMember: tier name from the tooltip
+	Months     int         // BadgeMember: membership duration, 0 for new members
+}
+
+// Role is a set of author roles
+type Role uint8
+
+const (
+	RoleOwner Role = 1 << iota
+	RoleModerator
+	RoleMember
+	RoleVerified
+	RoleArtist
+)
+
+// Has reports whether every role of role is set
+func (r Role) Has(role Role) bool { return r&role == role }
+
+func (r Role) IsOwner() bool     { return r.Has(RoleOwner) }
+func (r Role) IsModerator() bool { return r.Has(RoleModerator) }
+func (r Role) IsMember() bool    { return r.Has(RoleMember) }
+func (r Role) IsVerified() bool  { return r.Has(RoleVerified) }
+func (r Role) IsArtist() bool    { return r.Has(RoleArtist) }
+
+// CanModerate reports an owner or a moderator
+func (r Role) CanModerate() bool { return r&(RoleOwner|RoleModerator) != 0 }
+
+func (r Role) String() string {
+	var names []string
+	for _, role := range []struct {
+		role Role
+		name string
+	}{
+		{RoleOwner, "owner"},
+		{RoleModerator, "moderator"},
+		{RoleMember, "member"},
+		{RoleVerified, "verified"},
+		{RoleArtist, "artist"},
+	} {
+		if r.Has(role.role) {
+			names = append(names, role.name)
+		}
+	}
+	if len(names) == 0 {
+		return "viewer"
+	}
+	return strings.Join(names, "|")
 }
 
 type SuperChat struct {
-	Amount  string
-	Color   string
-	Sticker *ImageItem
+	Amount   string // as displayed, see Money for the parsed value
+	Money    Money
+	Color    string // body background as "#RRGGBB", see Colors for the full palette
+	Colors   SuperChatColors
+	Tier     SuperChatTier
+	Duration time.Duration // how long it stays pinned in the ticker
+	Sticker  *ImageItem
+}
+
+// SuperChatColors is the palette YouTube styles a Super Chat with
+type SuperChatColors struct {
+	HeaderBackground    Color
+	HeaderText          Color
+	BodyBackground      Color // the sticker background for Super Stickers
+	BodyText            Color
+	AuthorName          Color
+	Timestamp           Color
+	MoneyChipBackground Color // Super Stickers only
+	MoneyChipText       Color // Super Stickers only
+}
+
+// Color is a 32-bit ARGB color as sent by YouTube
+type Color uint32
+
+// RGBA splits the color in its components
+func (c Color) RGBA() (r, g, b, a uint8) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), uint8(c >> 24)
+}
+
+// Hex formats the color as "#RRGGBB", dropping the alpha
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%06X", uint32(c)&0xFFFFFF)
+}
+
+// HexAlpha formats the color as CSS "#RRGGBBAA"
+func (c Color) HexAlpha() string {
+	r, g, b, a := c.RGBA()
+	return fmt.Sprintf("#%02X%02X%02X%02X", r, g, b, a)
+}
+
+// SuperChatTier is the color band YouTube assigns from the paid amount
+type SuperChatTier int
+
+const (
+	SuperChatTierUnknown SuperChatTier = iota
+	SuperChatTierBlue
+	SuperChatTierCyan
+	SuperChatTierGreen
+	SuperChatTierYellow
+	SuperChatTierOrange
+	SuperChatTierMagenta
+	SuperChatTierRed
+)
+
+func (t SuperChatTier) String() string {
+	switch t {
+	case SuperChatTierBlue:
+		return "blue"
+	case SuperChatTierCyan:
+		return "cyan"
+	case SuperChatTierGreen:
+		return "green"
+	case SuperChatTierYellow:
+		return "yellow"
+	case SuperChatTierOrange:
+		return "orange"
+	case SuperChatTierMagenta:
+		return "magenta"
+	case SuperChatTierRed:
+		return "red"
+	}
+	return "unknown"
+}
+
+// Money is a parsed display amount. Currency is empty when it could not be
+// recognized from the display symbol.
+type Money struct {
+	Currency string // ISO 4217 code
+	Micros   int64  // amount in millionths of the currency unit
+	Display  string
+}
+
+// MembershipKind tells a new member from a membership milestone
+type MembershipKind int
+
+const (
+	MembershipNew MembershipKind = iota + 1
+	MembershipMilestone
+)
+
+// Membership is set on membership announcements. For a milestone the
+// ChatItem's Message holds the member's own comment, if any.
+type Membership struct {
+	Kind   MembershipKind
+	Tier   string
+	Months int           // set for MembershipMilestone
+	Header []MessageItem // "Welcome to Tier!" or "Member for 24 months"
+}
+
+// RedirectKind tells a raid into this chat from viewers sent elsewhere
+type RedirectKind int
+
+const (
+	RedirectIncoming RedirectKind = iota + 1 // another stream raided this one
+	RedirectOutgoing                         // this stream sends its viewers away
+)
+
+// Redirect is decoded from a redirect banner. The side that is the observed
+// stream is filled in by LiveChat when the channel is known.
+type Redirect struct {
+	ID            string // ID of the banner, see BannerRemoved
+	Kind          RedirectKind
+	Source        Channel
+	Target        Channel
+	TargetVideoID string     // set for RedirectOutgoing when the banner links a stream
+	Avatar        *ImageItem // the other channel's avatar
+	Message       []MessageItem
+	Raw           json.RawMessage
+}
+
+// GiftKind tells a gift purchase from its redemption
+type GiftKind int
+
+const (
+	GiftPurchase GiftKind = iota + 1
+	GiftRedemption
+)
+
+// Gift is set on chat items announcing gifted memberships.
+// The item's Author is the gifter for a purchase and the recipient for a redemption.
+type Gift struct {
+	Kind      GiftKind
+	Gifter    string
+	Recipient string // set for GiftRedemption
+	Count     int    // set for GiftPurchase
+	Tier      string // membership name shown in the purchase announcement
+}
+
+// SystemMessageKind tells which notice a SystemMessage is
+type SystemMessageKind int
+
+const (
+	SystemUnknown SystemMessageKind = iota
+	SystemSlowMode
+	SystemSubscribersOnly
+	SystemMembersOnly
+	SystemEngagement
+	SystemPlaceholder
+)
+
+// ModeState tells whether a mode change turned the mode on or off
+type ModeState int
+
+const (
+	ModeUnknown ModeState = iota
+	ModeOn
+	ModeOff
+)
+
+// SystemMessage is set on author-less chat items posted by YouTube itself.
+// Placeholders are usually replaced later by a Replacement with the same ID.
+type SystemMessage struct {
+	Kind    SystemMessageKind
+	State   ModeState     // for mode changes, ModeUnknown when the text is not understood
+	Delay   time.Duration // slow mode interval between messages, when known
+	Icon    string        // YouTube icon type, e.g. "SLOW_MODE"
+	Text    []MessageItem
+	Subtext []MessageItem
 }
 
 // MessageItem represents a chat message string or emoji
@@ -38,6 +591,71 @@ type SuperChat struct {
 type MessageItem struct {
 	Text      string
 	EmojiItem *EmojiItem
+	Kind      RunKind
+	URL       string // RunLink and RunMention target, YouTube redirects resolved
+	ChannelID string // RunMention, or a RunLink to a channel
+	VideoID   string // RunLink to a video
+
+	Bold          bool
+	Italic        bool
+	Strikethrough bool
+}
+
+// RunKind tells what a MessageItem holds
+type RunKind int
+
+const (
+	RunText    RunKind = iota
+	RunEmoji           // EmojiItem is set
+	RunLink            // Text links to URL
+	RunMention         // Text is an @handle of ChannelID
+)
+
+// EmojiStyle selects how PlainText writes emoji
+type EmojiStyle int
+
+const (
+	EmojiAsParsed    EmojiStyle = iota // EmojiText, as parsed
+	EmojiAsUnicode                     // standard emoji as Unicode, custom emoji as :shortcut:
+	EmojiAsShortcode                   // every emoji as :shortcut:
+)
+
+// PlainText returns the text of the item, writing emoji in the given style.
+// Emoji without a known Unicode sequence or shortcut keep their EmojiText.
+func (m MessageItem) PlainText(style EmojiStyle) string {
+	e := m.EmojiItem
+	if e == nil {
+		return m.Text
+	}
+
+	shortcut := ""
+	if len(e.Shortcuts) > 0 {
+		shortcut = e.Shortcuts[0]
+	}
+
+	switch style {
+	case EmojiAsUnicode:
+		if !e.IsCustomEmoji && e.Unicode != "" {
+			return e.Unicode
+		}
+		if shortcut != "" {
+			return shortcut
+		}
+	case EmojiAsShortcode:
+		if shortcut != "" {
+			return shortcut
+		}
+	}
+	return e.EmojiText
+}
+
+// PlainText joins the items, see MessageItem.PlainText
+func PlainText(items []MessageItem, style EmojiStyle) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(item.PlainText(style))
+	}
+	return b.String()
 }
 
 // ImageItem represents an image
@@ -46,11 +664,51 @@ type ImageItem struct {
 	Alt string
 }
 
-// EmojiItem represents an emoji
+// EmojiItem represents an emoji. The embedded ImageItem is the first
+// thumbnail, use BestImage to pick another size.
 type EmojiItem struct {
 	ImageItem
-	EmojiText     string
-	IsCustomEmoji bool
+	EmojiText        string
+	IsCustomEmoji    bool
+	Unicode          string // standard emoji as Unicode, empty when unknown or custom
+	ID               string
+	Label            string      // accessibility label
+	Thumbnails       []Thumbnail // every size sent, Width and Height are 0 when unknown
+	Shortcuts        []string
+	SearchTerms      []string
+	VariantIDs       []string // skin-tone variants, the emoji itself included
+	SupportsSkinTone bool
+}
+
+// BestImage returns the smallest thumbnail at least size pixels wide, or the
+// largest one when none is big enough. Thumbnails of unknown size, such as the
+// SVGs of standard emoji, are only picked when no size is known.
+func (e *EmojiItem) BestImage(size int) Thumbnail {
+	if len(e.Thumbnails) == 0 {
+		return Thumbnail{URL: e.URL}
+	}
+
+	best := -1
+	for i, t := range e.Thumbnails {
+		if t.Width == 0 {
+			continue
+		}
+		switch {
+		case best < 0:
+			best = i
+		case e.Thumbnails[best].Width < size:
+			// Not big enough yet, anything larger is better
+			if t.Width > e.Thumbnails[best].Width {
+				best = i
+			}
+		case t.Width >= size && t.Width < e.Thumbnails[best].Width:
+			best = i
+		}
+	}
+	if best < 0 {
+		return e.Thumbnails[0]
+	}
+	return e.Thumbnails[best]
 }
 
 // YoutubeId union type in TS: { channelId: string } | { liveId: string } | { handle: string }
@@ -59,4 +717,24 @@ type YoutubeId struct {
 	ChannelID string
 	LiveID    string
 	Handle    string
+	CustomURL string // name from a youtube.com/c/<name> URL
+}
+
+// Channel identifies the channel owning a page
+type Channel struct {
+	ID     string // stable UC... channel ID
+	Handle string // current @handle, empty if the page does not expose one
+	Title  string
 }
+
+// ChatMode selects which view of the chat is observed
+type ChatMode int
+
+const (
+	// ChatModeDefault uses the first continuation found on the page
+	ChatModeDefault ChatMode = iota
+	// ChatModeTop is the filtered "Top chat" view
+	ChatModeTop
+	// ChatModeLive is the unfiltered "Live chat" view with every message
+	ChatModeLive
+)
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // GetLiveChatResponse represents the API response
 type GetLiveChatResponse struct {
 	ResponseContext      interface{} `json:"responseContext"`
@@ -29,11 +34,73 @@ type Continuation struct {
 		Continuation        string `json:"continuation"`
 		ClickTrackingParams string `json:"clickTrackingParams"`
 	} `json:"timedContinuationData,omitempty"`
+	ReloadContinuationData *struct {
+		Continuation        string `json:"continuation"`
+		ClickTrackingParams string `json:"clickTrackingParams"`
+	} `json:"reloadContinuationData,omitempty"`
 }
 
 type Action struct {
-	AddChatItemAction           *AddChatItemAction `json:"addChatItemAction,omitempty"`
-	AddLiveChatTickerItemAction interface{}        `json:"addLiveChatTickerItemAction,omitempty"`
+	AddChatItemAction                    *AddChatItemAction                    `json:"addChatItemAction,omitempty"`
+	AddLiveChatTickerItemAction          *AddLiveChatTickerItemAction          `json:"addLiveChatTickerItemAction,omitempty"`
+	MarkChatItemAsDeletedAction          *MarkChatItemAsDeletedAction          `json:"markChatItemAsDeletedAction,omitempty"`
+	MarkChatItemsByAuthorAsDeletedAction *MarkChatItemsByAuthorAsDeletedAction `json:"markChatItemsByAuthorAsDeletedAction,omitempty"`
+	ReplaceChatItemAction                *ReplaceChatItemAction                `json:"replaceChatItemAction,omitempty"`
+	ShowLiveChatActionPanelAction        *ShowLiveChatActionPanelAction        `json:"showLiveChatActionPanelAction,omitempty"`
+	UpdateLiveChatPollAction             *UpdateLiveChatPollAction             `json:"updateLiveChatPollAction,omitempty"`
+	CloseLiveChatActionPanelAction       *CloseLiveChatActionPanelAction       `json:"closeLiveChatActionPanelAction,omitempty"`
+	AddBannerToLiveChatCommand           *AddBannerToLiveChatCommand           `json:"addBannerToLiveChatCommand,omitempty"`
+	RemoveBannerForLiveChatCommand       *RemoveBannerForLiveChatCommand       `json:"removeBannerForLiveChatCommand,omitempty"`
+	ReplayChatItemAction                 *ReplayChatItemAction                 `json:"replayChatItemAction,omitempty"`
+
+	// Raw is the action JSON. It is kept for actions that did not decode into
+	// a modeled renderer, and for every action when the parser is asked to.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON keeps the raw JSON of actions that cannot be decoded, so that
+// they can still be reported
+func (a *Action) UnmarshalJSON(data []byte) error {
+	type action Action
+	if err := json.Unmarshal(data, (*action)(a)); err != nil {
+		return err
+	}
+	if !a.modeled() {
+		a.Raw = append(json.RawMessage(nil), data...)
+	}
+	return nil
+}
+
+// modeled reports whether the action and the renderer it carries, if any,
+// decoded into one of the structs below
+func (a *Action) modeled() bool {
+	switch {
+	case a.AddChatItemAction != nil:
+		return a.AddChatItemAction.Item.modeled()
+	case a.ReplaceChatItemAction != nil:
+		return a.ReplaceChatItemAction.ReplacementItem.modeled()
+	case a.AddLiveChatTickerItemAction != nil:
+		item := a.AddLiveChatTickerItemAction.Item
+		return item.LiveChatTickerPaidMessageItemRenderer != nil || item.LiveChatTickerPaidStickerItemRenderer != nil ||
+			item.LiveChatTickerSponsorItemRenderer != nil
+	case a.AddBannerToLiveChatCommand != nil:
+		r := a.AddBannerToLiveChatCommand.BannerRenderer.LiveChatBannerRenderer
+		return r != nil && (r.Contents.ActionItem.modeled() || r.Contents.LiveChatBannerChatSummaryRenderer != nil ||
+			r.Contents.LiveChatBannerRedirectRenderer != nil)
+	case a.ShowLiveChatActionPanelAction != nil:
+		r := a.ShowLiveChatActionPanelAction.PanelToShow.LiveChatActionPanelRenderer
+		return r != nil && r.Contents.modeled()
+	case a.UpdateLiveChatPollAction != nil:
+		return a.UpdateLiveChatPollAction.PollToUpdate.modeled()
+	}
+	return a.MarkChatItemAsDeletedAction != nil || a.MarkChatItemsByAuthorAsDeletedAction != nil ||
+		a.CloseLiveChatActionPanelAction != nil || a.RemoveBannerForLiveChatCommand != nil || a.ReplayChatItemAction != nil
+}
+
+// ReplayChatItemAction wraps the actions of a replay at their video offset
+type ReplayChatItemAction struct {
+	Actions             []Action `json:"actions"`
+	VideoOffsetTimeMsec string   `json:"videoOffsetTimeMsec"`
 }
 
 type AddChatItemAction struct {
@@ -41,12 +108,237 @@ type AddChatItemAction struct {
 	ClientId string     `json:"clientId"`
 }
 
+type AddLiveChatTickerItemAction struct {
+	Item struct {
+		LiveChatTickerPaidMessageItemRenderer *LiveChatTickerPaidMessageItemRenderer `json:"liveChatTickerPaidMessageItemRenderer,omitempty"`
+		LiveChatTickerPaidStickerItemRenderer *LiveChatTickerPaidStickerItemRenderer `json:"liveChatTickerPaidStickerItemRenderer,omitempty"`
+		LiveChatTickerSponsorItemRenderer     *LiveChatTickerSponsorItemRenderer     `json:"liveChatTickerSponsorItemRenderer,omitempty"`
+	} `json:"item"`
+	DurationSec string `json:"durationSec"`
+}
+
+type TickerRendererBase struct {
+	ID                      string `json:"id"`
+	AuthorExternalChannelId string `json:"authorExternalChannelId"`
+	DurationSec             int    `json:"durationSec"`
+	FullDurationSec         int    `json:"fullDurationSec"`
+	StartBackgroundColor    int    `json:"startBackgroundColor"`
+	EndBackgroundColor      int    `json:"endBackgroundColor"`
+	ShowItemEndpoint        struct {
+		ShowLiveChatItemEndpoint struct {
+			Renderer ActionItem `json:"renderer"`
+		} `json:"showLiveChatItemEndpoint"`
+	} `json:"showItemEndpoint"`
+}
+
+type LiveChatTickerPaidMessageItemRenderer struct {
+	TickerRendererBase
+	Amount struct {
+		SimpleText string `json:"simpleText"`
+	} `json:"amount"`
+	AmountTextColor int `json:"amountTextColor"`
+	AuthorPhoto     struct {
+		Thumbnails []Thumbnail `json:"thumbnails"`
+	} `json:"authorPhoto"`
+}
+
+type LiveChatTickerPaidStickerItemRenderer struct {
+	TickerRendererBase
+	AuthorPhoto struct {
+		Thumbnails []Thumbnail `json:"thumbnails"`
+	} `json:"authorPhoto"`
+	TickerThumbnails []struct {
+		Thumbnails    []Thumbnail `json:"thumbnails"`
+		Accessibility struct {
+			AccessibilityData struct {
+				Label string `json:"label"`
+			} `json:"accessibilityData"`
+		} `json:"accessibility"`
+	} `json:"tickerThumbnails"`
+}
+
+type LiveChatTickerSponsorItemRenderer struct {
+	TickerRendererBase
+	DetailText struct {
+		SimpleText string       `json:"simpleText,omitempty"`
+		Runs       []MessageRun `json:"runs,omitempty"`
+	} `json:"detailText"`
+	DetailTextColor int `json:"detailTextColor"`
+	SponsorPhoto    struct {
+		Thumbnails []Thumbnail `json:"thumbnails"`
+	} `json:"sponsorPhoto"`
+}
+
+type AddBannerToLiveChatCommand struct {
+	BannerRenderer struct {
+		LiveChatBannerRenderer *LiveChatBannerRenderer `json:"liveChatBannerRenderer,omitempty"`
+	} `json:"bannerRenderer"`
+}
+
+type RemoveBannerForLiveChatCommand struct {
+	TargetActionId string `json:"targetActionId"`
+}
+
+type LiveChatBannerRenderer struct {
+	Header *struct {
+		LiveChatBannerHeaderRenderer struct {
+			Icon *struct {
+				IconType string `json:"iconType"`
+			} `json:"icon,omitempty"`
+			Text struct {
+				Runs []MessageRun `json:"runs"`
+			} `json:"text"`
+		} `json:"liveChatBannerHeaderRenderer"`
+	} `json:"header,omitempty"`
+	Contents struct {
+		ActionItem
+		LiveChatBannerChatSummaryRenderer *LiveChatBannerChatSummaryRenderer `json:"liveChatBannerChatSummaryRenderer,omitempty"`
+		LiveChatBannerRedirectRenderer    *LiveChatBannerRedirectRenderer    `json:"liveChatBannerRedirectRenderer,omitempty"`
+	} `json:"contents"`
+	ActionId        string `json:"actionId"`
+	ViewerIsCreator bool   `json:"viewerIsCreator"`
+	TargetId        string `json:"targetId"`
+	IsStackable     bool   `json:"isStackable"`
+	BackgroundType  string `json:"backgroundType"`
+}
+
+type LiveChatBannerChatSummaryRenderer struct {
+	LiveChatSummaryId string `json:"liveChatSummaryId"`
+	ChatSummary       struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"chatSummary"`
+}
+
+type LiveChatBannerRedirectRenderer struct {
+	BannerMessage struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"bannerMessage"`
+	AuthorPhoto struct {
+		Thumbnails []Thumbnail `json:"thumbnails"`
+	} `json:"authorPhoto"`
+	// Only outgoing redirects link to the target stream
+	InlineActionButton *struct {
+		ButtonRenderer struct {
+			Text struct {
+				Runs []MessageRun `json:"runs"`
+			} `json:"text"`
+			Command NavigationEndpoint `json:"command"`
+		} `json:"buttonRenderer"`
+	} `json:"inlineActionButton,omitempty"`
+}
+
+type NavigationEndpoint struct {
+	CommandMetadata *struct {
+		WebCommandMetadata struct {
+			URL string `json:"url"`
+		} `json:"webCommandMetadata"`
+	} `json:"commandMetadata,omitempty"`
+	UrlEndpoint *struct {
+		URL string `json:"url"`
+	} `json:"urlEndpoint,omitempty"`
+	WatchEndpoint *struct {
+		VideoId string `json:"videoId"`
+	} `json:"watchEndpoint,omitempty"`
+	BrowseEndpoint *struct {
+		BrowseId         string `json:"browseId"`
+		CanonicalBaseUrl string `json:"canonicalBaseUrl,omitempty"`
+	} `json:"browseEndpoint,omitempty"`
+}
+
+type ShowLiveChatActionPanelAction struct {
+	PanelToShow struct {
+		LiveChatActionPanelRenderer *struct {
+			Contents PollContainer `json:"contents"`
+			ID       string        `json:"id"`
+			TargetId string        `json:"targetId"`
+		} `json:"liveChatActionPanelRenderer,omitempty"`
+	} `json:"panelToShow"`
+}
+
+func (c PollContainer) modeled() bool {
+	return c.PollRenderer != nil || c.LiveChatPollRenderer != nil
+}
+
+type UpdateLiveChatPollAction struct {
+	PollToUpdate PollContainer `json:"pollToUpdate"`
+}
+
+type CloseLiveChatActionPanelAction struct {
+	TargetPanelId        string `json:"targetPanelId"`
+	SkipOnDismissCommand bool   `json:"skipOnDismissCommand"`
+}
+
+// PollContainer holds a poll under either of the keys YouTube has used for it
+type PollContainer struct {
+	PollRenderer         *PollRenderer `json:"pollRenderer,omitempty"`
+	LiveChatPollRenderer *PollRenderer `json:"liveChatPollRenderer,omitempty"`
+}
+
+type PollRenderer struct {
+	Choices []struct {
+		Text struct {
+			Runs []MessageRun `json:"runs"`
+		} `json:"text"`
+		Selected       bool    `json:"selected"`
+		VoteRatio      float64 `json:"voteRatio"`
+		VotePercentage struct {
+			SimpleText string `json:"simpleText"`
+		} `json:"votePercentage"`
+	} `json:"choices"`
+	LiveChatPollId string `json:"liveChatPollId"`
+	Header         struct {
+		PollHeaderRenderer struct {
+			PollQuestion struct {
+				Runs []MessageRun `json:"runs"`
+			} `json:"pollQuestion"`
+			Thumbnail struct {
+				Thumbnails []Thumbnail `json:"thumbnails"`
+			} `json:"thumbnail"`
+			MetadataText struct {
+				Runs []MessageRun `json:"runs"`
+			} `json:"metadataText"`
+			LiveChatPollType string `json:"liveChatPollType"`
+		} `json:"pollHeaderRenderer"`
+	} `json:"header"`
+}
+
+type ReplaceChatItemAction struct {
+	TargetItemId    string     `json:"targetItemId"`
+	ReplacementItem ActionItem `json:"replacementItem"`
+}
+
+type MarkChatItemAsDeletedAction struct {
+	DeletedStateMessage struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"deletedStateMessage"`
+	TargetItemId string `json:"targetItemId"`
+}
+
+type MarkChatItemsByAuthorAsDeletedAction struct {
+	DeletedStateMessage struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"deletedStateMessage"`
+	ExternalChannelId string `json:"externalChannelId"`
+}
+
 type ActionItem struct {
 	LiveChatTextMessageRenderer             *LiveChatTextMessageRenderer    `json:"liveChatTextMessageRenderer,omitempty"`
 	LiveChatPaidMessageRenderer             *LiveChatPaidMessageRenderer    `json:"liveChatPaidMessageRenderer,omitempty"`
 	LiveChatMembershipItemRenderer          *LiveChatMembershipItemRenderer `json:"liveChatMembershipItemRenderer,omitempty"`
 	LiveChatPaidStickerRenderer             *LiveChatPaidStickerRenderer    `json:"liveChatPaidStickerRenderer,omitempty"`
-	LiveChatViewerEngagementMessageRenderer interface{}                     `json:"liveChatViewerEngagementMessageRenderer,omitempty"`
+	LiveChatViewerEngagementMessageRenderer *LiveChatSystemMessageRenderer  `json:"liveChatViewerEngagementMessageRenderer,omitempty"`
+	LiveChatModeChangeMessageRenderer       *LiveChatSystemMessageRenderer  `json:"liveChatModeChangeMessageRenderer,omitempty"`
+	LiveChatPlaceholderItemRenderer         *LiveChatSystemMessageRenderer  `json:"liveChatPlaceholderItemRenderer,omitempty"`
+
+	LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer   *LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer   `json:"liveChatSponsorshipsGiftPurchaseAnnouncementRenderer,omitempty"`
+	LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer *LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer `json:"liveChatSponsorshipsGiftRedemptionAnnouncementRenderer,omitempty"`
+}
+
+func (i ActionItem) modeled() bool {
+	return i.LiveChatTextMessageRenderer != nil || i.LiveChatPaidMessageRenderer != nil || i.LiveChatMembershipItemRenderer != nil ||
+		i.LiveChatPaidStickerRenderer != nil || i.LiveChatViewerEngagementMessageRenderer != nil ||
+		i.LiveChatModeChangeMessageRenderer != nil || i.LiveChatPlaceholderItemRenderer != nil ||
+		i.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer != nil || i.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer != nil
 }
 
 type Thumbnail struct {
@@ -77,8 +369,12 @@ type MessageEmoji struct {
 }
 
 type MessageRun struct {
-	Text  string        `json:"text,omitempty"`
-	Emoji *MessageEmoji `json:"emoji,omitempty"`
+	Text               string              `json:"text,omitempty"`
+	Emoji              *MessageEmoji       `json:"emoji,omitempty"`
+	NavigationEndpoint *NavigationEndpoint `json:"navigationEndpoint,omitempty"`
+	Bold               bool                `json:"bold,omitempty"`
+	Italics            bool                `json:"italics,omitempty"`
+	Strikethrough      bool                `json:"strikethrough,omitempty"`
 }
 
 type AuthorBadge struct {
@@ -127,6 +423,26 @@ type MessageRendererBase struct {
 	} `json:"contextMenuAccessibility"`
 }
 
+// LiveChatSystemMessageRenderer covers the author-less engagement, mode change
+// and placeholder renderers. Engagement notices use Message, mode changes
+// use Text and Subtext.
+type LiveChatSystemMessageRenderer struct {
+	ID            string `json:"id"`
+	TimestampUsec string `json:"timestampUsec"`
+	Icon          *struct {
+		IconType string `json:"iconType"`
+	} `json:"icon,omitempty"`
+	Message *struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"message,omitempty"`
+	Text *struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"text,omitempty"`
+	Subtext *struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"subtext,omitempty"`
+}
+
 type LiveChatTextMessageRenderer struct {
 	MessageRendererBase
 	Message struct {
@@ -144,6 +460,7 @@ type LiveChatPaidMessageRenderer struct {
 	BodyBackgroundColor   int `json:"bodyBackgroundColor"`
 	BodyTextColor         int `json:"bodyTextColor"`
 	AuthorNameTextColor   int `json:"authorNameTextColor"`
+	TimestampColor        int `json:"timestampColor"`
 }
 
 type LiveChatPaidStickerRenderer struct {
@@ -170,8 +487,106 @@ type LiveChatPaidStickerRenderer struct {
 type LiveChatMembershipItemRenderer struct {
 	MessageRendererBase
 	HeaderSubtext struct {
-		Runs []MessageRun `json:"runs"`
+		SimpleText string       `json:"simpleText,omitempty"`
+		Runs       []MessageRun `json:"runs"`
 	} `json:"headerSubtext"`
+	// Set for milestones only
+	HeaderPrimaryText *struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"headerPrimaryText,omitempty"`
+	Message *struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"message,omitempty"`
+}
+
+type LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer struct {
+	ID                      string `json:"id"`
+	TimestampUsec           string `json:"timestampUsec"`
+	AuthorExternalChannelId string `json:"authorExternalChannelId"`
+	Header                  struct {
+		LiveChatSponsorshipsHeaderRenderer struct {
+			MessageRendererBase
+			PrimaryText struct {
+				Runs []MessageRun `json:"runs"`
+			} `json:"primaryText"`
+			Image struct {
+				Thumbnails []Thumbnail `json:"thumbnails"`
+			} `json:"image"`
+		} `json:"liveChatSponsorshipsHeaderRenderer"`
+	} `json:"header"`
+}
+
+type LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer struct {
+	MessageRendererBase
+	Message struct {
+		Runs []MessageRun `json:"runs"`
+	} `json:"message"`
+}
+
+// NextResponse represents the innertube next API response, trimmed to what the chat needs
+type NextResponse struct {
+	CurrentVideoEndpoint struct {
+		WatchEndpoint struct {
+			VideoId string `json:"videoId"`
+		} `json:"watchEndpoint"`
+	} `json:"currentVideoEndpoint"`
+	Contents struct {
+		TwoColumnWatchNextResults struct {
+			Results struct {
+				Results struct {
+					Contents []struct {
+						VideoSecondaryInfoRenderer *struct {
+							Owner struct {
+								VideoOwnerRenderer struct {
+									Title struct {
+										Runs []MessageRun `json:"runs"`
+									} `json:"title"`
+									NavigationEndpoint struct {
+										BrowseEndpoint struct {
+											BrowseId         string `json:"browseId"`
+											CanonicalBaseUrl string `json:"canonicalBaseUrl"`
+										} `json:"browseEndpoint"`
+									} `json:"navigationEndpoint"`
+								} `json:"videoOwnerRenderer"`
+							} `json:"owner"`
+						} `json:"videoSecondaryInfoRenderer,omitempty"`
+					} `json:"contents"`
+				} `json:"results"`
+			} `json:"results"`
+			ConversationBar *struct {
+				LiveChatRenderer        *LiveChatRenderer `json:"liveChatRenderer,omitempty"`
+				ConversationBarRenderer interface{}       `json:"conversationBarRenderer,omitempty"`
+			} `json:"conversationBar,omitempty"`
+		} `json:"twoColumnWatchNextResults"`
+	} `json:"contents"`
+}
+
+// LiveChatRenderer is the chat as embedded in the watch page, the next response
+// and the live_chat popout page. Only the popout page includes Actions.
+type LiveChatRenderer struct {
+	Continuations []Continuation `json:"continuations"`
+	Actions       []Action       `json:"actions,omitempty"`
+	Header        struct {
+		LiveChatHeaderRenderer struct {
+			ViewSelector struct {
+				SortFilterSubMenuRenderer struct {
+					SubMenuItems []struct {
+						Title        string       `json:"title"`
+						Selected     bool         `json:"selected"`
+						Continuation Continuation `json:"continuation"`
+					} `json:"subMenuItems"`
+				} `json:"sortFilterSubMenuRenderer"`
+			} `json:"viewSelector"`
+		} `json:"liveChatHeaderRenderer"`
+	} `json:"header"`
+	IsReplay bool `json:"isReplay,omitempty"`
+}
+
+// LiveChatPageData is the ytInitialData of the live_chat popout page
+type LiveChatPageData struct {
+	Contents struct {
+		LiveChatRenderer *LiveChatRenderer `json:"liveChatRenderer,omitempty"`
+	} `json:"contents"`
 }
 
 // FetchOptions for get_live_chat
@@ -180,4 +595,14 @@ type FetchOptions struct {
 	ClientVersion string
 	Continuation  string
 	LiveID        string // Added to store liveID as in parser.ts return type
+	Channel       Channel
+
+	// Continuations listed in the chat header's view selector.
+	// Empty when the page has no view selector.
+	TopChatContinuation  string
+	LiveChatContinuation string
+
+	// StartTime is when the stream went live, zero when the page does not
+	// tell (e.g. from the innertube next endpoint)
+	StartTime time.Time
 }
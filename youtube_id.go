@@ -0,0 +1,116 @@
+package youtubechat
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+var (
+	regexVideoID   = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	regexChannelID = regexp.MustCompile(`^UC[A-Za-z0-9_-]{22}$`)
+	regexHandle    = regexp.MustCompile(`^@[\p{L}\p{N}._-]{3,30}$`)
+	regexCustomURL = regexp.MustCompile(`^[\p{L}\p{N}._-]+$`)
+)
+
+var youtubeHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"gaming.youtube.com":       true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+}
+
+// ParseYoutubeId builds a YoutubeId from any URL or identifier form a user may paste:
+// watch, youtu.be, /live/, /embed/, /shorts/, /channel/, /@handle, /c/ and
+// live_chat popout URLs, as well as bare video IDs, channel IDs and @handles.
+func ParseYoutubeId(input string) (types.YoutubeId, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return types.YoutubeId{}, fmt.Errorf("empty youtube id")
+	}
+
+	// Bare identifiers
+	switch {
+	case regexChannelID.MatchString(s):
+		return types.YoutubeId{ChannelID: s}, nil
+	case regexVideoID.MatchString(s):
+		return types.YoutubeId{LiveID: s}, nil
+	case strings.HasPrefix(s, "@"):
+		if !regexHandle.MatchString(s) {
+			return types.YoutubeId{}, fmt.Errorf("invalid handle %q", s)
+		}
+		return types.YoutubeId{Handle: s}, nil
+	}
+
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return types.YoutubeId{}, fmt.Errorf("unrecognized youtube id or url %q", input)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+
+	if host == "youtu.be" {
+		if len(segments) == 0 {
+			return types.YoutubeId{}, fmt.Errorf("missing video id in %q", input)
+		}
+		return videoID(segments[0])
+	}
+
+	if !youtubeHosts[host] {
+		return types.YoutubeId{}, fmt.Errorf("not a youtube url %q", input)
+	}
+
+	if len(segments) == 0 {
+		return types.YoutubeId{}, fmt.Errorf("missing id in %q", input)
+	}
+
+	switch first := segments[0]; {
+	case first == "watch", first == "live_chat", first == "live_chat_replay":
+		v := u.Query().Get("v")
+		if v == "" {
+			return types.YoutubeId{}, fmt.Errorf("missing v parameter in %q", input)
+		}
+		return videoID(v)
+	case first == "live", first == "embed", first == "shorts", first == "v":
+		if len(segments) < 2 {
+			return types.YoutubeId{}, fmt.Errorf("missing video id in %q", input)
+		}
+		return videoID(segments[1])
+	case first == "channel":
+		if len(segments) < 2 || !regexChannelID.MatchString(segments[1]) {
+			return types.YoutubeId{}, fmt.Errorf("invalid channel id in %q", input)
+		}
+		return types.YoutubeId{ChannelID: segments[1]}, nil
+	case first == "c":
+		if len(segments) < 2 || !regexCustomURL.MatchString(segments[1]) {
+			return types.YoutubeId{}, fmt.Errorf("invalid custom url in %q", input)
+		}
+		return types.YoutubeId{CustomURL: segments[1]}, nil
+	case strings.HasPrefix(first, "@"):
+		handle, err := url.PathUnescape(first)
+		if err != nil || !regexHandle.MatchString(handle) {
+			return types.YoutubeId{}, fmt.Errorf("invalid handle in %q", input)
+		}
+		return types.YoutubeId{Handle: handle}, nil
+	}
+
+	return types.YoutubeId{}, fmt.Errorf("unsupported youtube url %q", input)
+}
+
+func videoID(id string) (types.YoutubeId, error) {
+	if !regexVideoID.MatchString(id) {
+		return types.YoutubeId{}, fmt.Errorf("invalid video id %q", id)
+	}
+	return types.YoutubeId{LiveID: id}, nil
+}
@@ -0,0 +1,66 @@
+package youtubechat
+
+import (
+	"testing"
+
+	"github.com/DiegPS/youtube-chat/types"
+)
+
+func TestParseYoutubeId(t *testing.T) {
+	const videoId = "dchqdFOW8EI"
+	const channelId = "UCxkOLgdNumvVIQqn5ps_bJA"
+
+	tests := []struct {
+		input    string
+		expected types.YoutubeId
+	}{
+		{"https://www.youtube.com/watch?v=dchqdFOW8EI", types.YoutubeId{LiveID: videoId}},
+		{"https://www.youtube.com/watch?feature=share&v=dchqdFOW8EI&t=10", types.YoutubeId{LiveID: videoId}},
+		{"https://m.youtube.com/watch?v=dchqdFOW8EI", types.YoutubeId{LiveID: videoId}},
+		{"youtube.com/watch?v=dchqdFOW8EI", types.YoutubeId{LiveID: videoId}},
+		{"https://youtu.be/dchqdFOW8EI?si=abc", types.YoutubeId{LiveID: videoId}},
+		{"https://www.youtube.com/live/dchqdFOW8EI?feature=share", types.YoutubeId{LiveID: videoId}},
+		{"https://www.youtube.com/embed/dchqdFOW8EI", types.YoutubeId{LiveID: videoId}},
+		{"https://www.youtube.com/shorts/dchqdFOW8EI", types.YoutubeId{LiveID: videoId}},
+		{"https://www.youtube.com/live_chat?is_popout=1&v=dchqdFOW8EI", types.YoutubeId{LiveID: videoId}},
+		{"https://www.youtube.com/channel/UCxkOLgdNumvVIQqn5ps_bJA/live", types.YoutubeId{ChannelID: channelId}},
+		{"https://www.youtube.com/@handle", types.YoutubeId{Handle: "@handle"}},
+		{"https://www.youtube.com/@handle/live", types.YoutubeId{Handle: "@handle"}},
+		{"https://www.youtube.com/c/Academind", types.YoutubeId{CustomURL: "Academind"}},
+		{"  dchqdFOW8EI  ", types.YoutubeId{LiveID: videoId}},
+		{"UCxkOLgdNumvVIQqn5ps_bJA", types.YoutubeId{ChannelID: channelId}},
+		{"@handle", types.YoutubeId{Handle: "@handle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			id, err := ParseYoutubeId(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if id != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, id)
+			}
+		})
+	}
+
+	invalid := []string{
+		"",
+		"handle",
+		"@a",
+		"https://example.com/watch?v=dchqdFOW8EI",
+		"https://www.youtube.com/watch",
+		"https://www.youtube.com/watch?v=short",
+		"https://youtu.be/",
+		"https://www.youtube.com/channel/notAChannel",
+		"https://www.youtube.com/feed/subscriptions",
+	}
+
+	for _, input := range invalid {
+		t.Run("Invalid "+input, func(t *testing.T) {
+			if _, err := ParseYoutubeId(input); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		})
+	}
+}